@@ -0,0 +1,337 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/utils/atomicfile"
+	"github.com/camaeel/kubectl-ctx/internal/utils/filelock"
+	"github.com/camaeel/kubectl-ctx/internal/utils/paths"
+)
+
+// DefaultMaxBackups is the number of backups kept before the oldest are pruned
+const DefaultMaxBackups = 20
+
+const (
+	manifestName = "manifest.json"
+	idFormat     = "20060102-150405.000000"
+)
+
+// File is a kubeconfig file captured in a backup
+type File struct {
+	// Path is the absolute path of the kubeconfig file
+	Path string `json:"path"`
+	// Existed is false when the file did not exist; restoring removes it
+	Existed bool `json:"existed"`
+	// Name is the name of the copy inside the backup directory
+	Name string `json:"name,omitempty"`
+}
+
+// Backup is a snapshot of kubeconfig files taken before they were modified
+type Backup struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+	Files   []File    `json:"files"`
+}
+
+// Store manages a rotating directory of backups
+type Store struct {
+	// Dir holds one subdirectory per backup
+	Dir string
+	// Max is the number of backups kept
+	Max int
+	// DryRun, when set, receives the changes Restore and Undo would make
+	// instead of them being written
+	DryRun func(changes []kubeconfig.FileChange) error
+}
+
+// NewStore creates a store in the tool's state directory
+func NewStore() (*Store, error) {
+	dir, err := paths.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Dir: filepath.Join(dir, "backups"), Max: DefaultMaxBackups}, nil
+}
+
+// Snapshot copies the given files into a new backup and prunes old backups.
+// Callers must hold the kubeconfig locks of the files.
+func (s *Store) Snapshot(files []string) (*Backup, error) {
+	created := time.Now().UTC()
+	dir, id, err := s.createDir(created)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Backup{ID: id, Created: created}
+	for i, path := range files {
+		// KUBECONFIG may list relative paths, restores can run elsewhere
+		path = kubeconfig.AbsPath(path)
+		f := File{Path: path}
+		content, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+		default:
+			f.Existed = true
+			f.Name = strconv.Itoa(i) + ".yaml"
+			if err := os.WriteFile(filepath.Join(dir, f.Name), content, 0600); err != nil {
+				_ = os.RemoveAll(dir)
+				return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+			}
+		}
+		b.Files = append(b.Files, f)
+	}
+
+	manifest, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, manifestName), manifest, 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+
+	slog.Debug("Created kubeconfig backup", "id", id, "files", files)
+	return b, s.prune()
+}
+
+// createDir creates a uniquely named directory for a new backup
+func (s *Store) createDir(created time.Time) (string, string, error) {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return "", "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	base := created.Format(idFormat)
+	for i := 0; ; i++ {
+		id := base
+		if i > 0 {
+			id += "-" + strconv.Itoa(i)
+		}
+		dir := filepath.Join(s.Dir, id)
+		err := os.Mkdir(dir, 0700)
+		if err == nil {
+			return dir, id, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+	}
+}
+
+// List returns all backups, newest first
+func (s *Store) List() ([]Backup, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]Backup, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		b, err := s.Get(entry.Name())
+		if err != nil {
+			slog.Warn("Skipping unreadable backup", "id", entry.Name(), "error", err)
+			continue
+		}
+		backups = append(backups, *b)
+	}
+
+	slices.SortFunc(backups, func(a, b Backup) int { return b.Created.Compare(a.Created) })
+	return backups, nil
+}
+
+// Get reads the manifest of a backup
+func (s *Store) Get(id string) (*Backup, error) {
+	if id == "" || filepath.Base(id) != id {
+		return nil, fmt.Errorf("invalid backup ID %q", id)
+	}
+
+	content, err := os.ReadFile(filepath.Join(s.Dir, id, manifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("backup %q not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var b Backup
+	if err := json.Unmarshal(content, &b); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest %q: %w", id, err)
+	}
+	return &b, nil
+}
+
+// Restore writes the files of a backup back in place. The current state of
+// those files is backed up first, so a restore can itself be undone.
+func (s *Store) Restore(id string) (*Backup, error) {
+	b, contents, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := filelock.Lock(b.paths())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if s.DryRun != nil {
+		return b, s.dryRun(b, contents)
+	}
+
+	if _, err := s.Snapshot(b.paths()); err != nil {
+		return nil, err
+	}
+
+	return b, restoreFiles(b, contents)
+}
+
+// Undo restores the latest backup and removes it, so repeated calls step
+// further back in history
+func (s *Store) Undo() (*Backup, error) {
+	backups, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, errors.New("no backups to restore")
+	}
+
+	b, contents, err := s.load(backups[0].ID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := filelock.Lock(b.paths())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if s.DryRun != nil {
+		return b, s.dryRun(b, contents)
+	}
+
+	if err := restoreFiles(b, contents); err != nil {
+		return nil, err
+	}
+
+	return b, os.RemoveAll(filepath.Join(s.Dir, b.ID))
+}
+
+// load reads a backup and the content of its files into memory
+func (s *Store) load(id string) (*Backup, map[string][]byte, error) {
+	b, err := s.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contents := make(map[string][]byte, len(b.Files))
+	for _, f := range b.Files {
+		if !f.Existed {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(s.Dir, id, f.Name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read backup of %s: %w", f.Path, err)
+		}
+		contents[f.Path] = content
+	}
+
+	return b, contents, nil
+}
+
+func (b *Backup) paths() []string {
+	paths := make([]string, 0, len(b.Files))
+	for _, f := range b.Files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+// dryRun reports the changes a restore would make through s.DryRun
+func (s *Store) dryRun(b *Backup, contents map[string][]byte) error {
+	changes := make([]kubeconfig.FileChange, 0, len(b.Files))
+	for _, f := range b.Files {
+		current, err := os.ReadFile(f.Path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if !bytes.Equal(current, contents[f.Path]) {
+			changes = append(changes, kubeconfig.FileChange{Path: f.Path, Before: current, After: contents[f.Path]})
+		}
+	}
+	return s.DryRun(changes)
+}
+
+func restoreFiles(b *Backup, contents map[string][]byte) error {
+	for _, f := range b.Files {
+		if !f.Existed {
+			if err := os.Remove(f.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err := atomicfile.Write(f.Path, contents[f.Path], 0600); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+		slog.Debug("Restored kubeconfig", "file", f.Path, "backup", b.ID)
+	}
+	return nil
+}
+
+// prune removes the oldest backups beyond the configured maximum
+func (s *Store) prune() error {
+	if s.Max <= 0 {
+		return nil
+	}
+
+	backups, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	for _, b := range backups[min(s.Max, len(backups)):] {
+		slog.Debug("Pruning kubeconfig backup", "id", b.ID)
+		if err := os.RemoveAll(filepath.Join(s.Dir, b.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Enable snapshots every file modified through kubeconfig.Update with
+// options into the default store. Failing to create a backup is logged but
+// doesn't block the write.
+func Enable(options *kubeconfig.UpdateOptions) error {
+	store, err := NewStore()
+	if err != nil {
+		return err
+	}
+
+	options.BeforeWrite = func(files []string) error {
+		if _, err := store.Snapshot(files); err != nil {
+			slog.Warn("Failed to back up kubeconfig", "error", err)
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, maxBackups int) *Store {
+	t.Helper()
+	return &Store{Dir: filepath.Join(t.TempDir(), "backups"), Max: maxBackups}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestNewStore(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+
+	store, err := NewStore()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/state", "kubectl-ctx", "backups"), store.Dir)
+	assert.Equal(t, DefaultMaxBackups, store.Max)
+}
+
+func TestSnapshotAndList(t *testing.T) {
+	store := newTestStore(t, 10)
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	missing := filepath.Join(dir, "missing")
+	writeFile(t, config, "v1")
+
+	b, err := store.Snapshot([]string{config, missing})
+	require.NoError(t, err)
+	require.Len(t, b.Files, 2)
+	assert.True(t, b.Files[0].Existed)
+	assert.False(t, b.Files[1].Existed)
+
+	writeFile(t, config, "v2")
+	b2, err := store.Snapshot([]string{config})
+	require.NoError(t, err)
+
+	backups, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, b2.ID, backups[0].ID, "newest backup first")
+	assert.Equal(t, b.ID, backups[1].ID)
+}
+
+func TestSnapshot_Prunes(t *testing.T) {
+	store := newTestStore(t, 3)
+	config := filepath.Join(t.TempDir(), "config")
+	writeFile(t, config, "v")
+
+	var ids []string
+	for range 5 {
+		b, err := store.Snapshot([]string{config})
+		require.NoError(t, err)
+		ids = append(ids, b.ID)
+	}
+
+	backups, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+	assert.Equal(t, ids[4], backups[0].ID)
+	assert.Equal(t, ids[2], backups[2].ID)
+}
+
+func TestUndo(t *testing.T) {
+	store := newTestStore(t, 10)
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	created := filepath.Join(dir, "created")
+
+	writeFile(t, config, "v1")
+	_, err := store.Snapshot([]string{config})
+	require.NoError(t, err)
+	writeFile(t, config, "v2")
+
+	_, err = store.Snapshot([]string{config, created})
+	require.NoError(t, err)
+	writeFile(t, config, "v3")
+	writeFile(t, created, "new")
+
+	_, err = store.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, "v2", readFile(t, config))
+	assert.NoFileExists(t, created, "files that did not exist are removed")
+
+	_, err = store.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, "v1", readFile(t, config))
+
+	_, err = store.Undo()
+	assert.Error(t, err, "no backups left")
+}
+
+func TestRestore_IsUndoable(t *testing.T) {
+	store := newTestStore(t, 10)
+	config := filepath.Join(t.TempDir(), "config")
+
+	writeFile(t, config, "v1")
+	b, err := store.Snapshot([]string{config})
+	require.NoError(t, err)
+	writeFile(t, config, "v2")
+
+	_, err = store.Restore(b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", readFile(t, config))
+
+	_, err = store.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, "v2", readFile(t, config))
+}
+
+func TestUndo_KeepsSymlinkAndMode(t *testing.T) {
+	store := newTestStore(t, 10)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles-config")
+	link := filepath.Join(dir, "config")
+
+	require.NoError(t, os.WriteFile(target, []byte("v1"), 0640))
+	require.NoError(t, os.Chmod(target, 0640))
+	require.NoError(t, os.Symlink(target, link))
+	_, err := store.Snapshot([]string{link})
+	require.NoError(t, err)
+	writeFile(t, link, "v2")
+
+	_, err = store.Undo()
+	require.NoError(t, err)
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type(), "the symlink must be kept")
+	assert.Equal(t, "v1", readFile(t, target))
+	info, err = os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+}
+
+func TestUndo_FromOtherDirectory(t *testing.T) {
+	store := newTestStore(t, 10)
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	writeFile(t, config, "v1")
+
+	// KUBECONFIG=config, relative to the directory of the switch
+	t.Chdir(dir)
+	_, err := store.Snapshot([]string{"config"})
+	require.NoError(t, err)
+	writeFile(t, config, "v2")
+
+	t.Chdir(t.TempDir())
+	_, err = store.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, "v1", readFile(t, config))
+	assert.NoFileExists(t, "config")
+}
+
+func TestRestore_UnknownID(t *testing.T) {
+	store := newTestStore(t, 10)
+
+	_, err := store.Restore("nope")
+	assert.Error(t, err)
+
+	_, err = store.Restore("../escape")
+	assert.Error(t, err)
+}
+
+func TestEnable(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	var options kubeconfig.UpdateOptions
+	require.NoError(t, Enable(&options))
+	require.NotNil(t, options.BeforeWrite)
+
+	config := filepath.Join(t.TempDir(), "config")
+	writeFile(t, config, "v1")
+	require.NoError(t, options.BeforeWrite([]string{config}))
+
+	store, err := NewStore()
+	require.NoError(t, err)
+	backups, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestUndo_DryRun(t *testing.T) {
+	store := newTestStore(t, 10)
+	config := filepath.Join(t.TempDir(), "config")
+
+	writeFile(t, config, "v1\n")
+	_, err := store.Snapshot([]string{config})
+	require.NoError(t, err)
+	writeFile(t, config, "v2\n")
+
+	var diffs []string
+	store.DryRun = kubeconfig.ReportDiffs(func(_, diff string) error {
+		diffs = append(diffs, diff)
+		return nil
+	})
+
+	_, err = store.Undo()
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Contains(t, diffs[0], "-v2\n+v1\n")
+	assert.Equal(t, "v2\n", readFile(t, config), "dry run must not restore")
+
+	backups, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, backups, 1, "dry run must not remove the backup")
+}
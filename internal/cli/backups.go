@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/backup"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// newUndoCommand returns the undo command
+func newUndoCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "undo",
+		Short: "Restore the kubeconfig files changed by the last switch",
+		Long: `Restore the most recent kubeconfig backup and remove it, so running undo
+again steps further back. Every switch backs up the files it modifies first.`,
+		Args: cobra.NoArgs,
+		RunE: runUndo,
+	}
+}
+
+// newBackupsCommand returns the backups command with its subcommands
+func newBackupsCommand() *cobra.Command {
+	backupsCmd := &cobra.Command{
+		Use:   "backups",
+		Short: "Manage kubeconfig backups",
+	}
+
+	backupsCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List kubeconfig backups, newest first",
+		Args:  cobra.NoArgs,
+		RunE:  runBackupsList,
+	}, &cobra.Command{
+		Use:   "restore ID",
+		Short: "Restore a kubeconfig backup",
+		Long: `Restore the files of a kubeconfig backup. The current state of those files
+is backed up first, so the restore can be undone.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBackupsRestore,
+	})
+
+	return backupsCmd
+}
+
+func runUndo(cmd *cobra.Command, _ []string) error {
+	store, err := backup.NewStore()
+	if err != nil {
+		return err
+	}
+	store.DryRun = configFlags.Write.DryRun
+
+	b, err := store.Undo()
+	if err != nil || dryRun {
+		return err
+	}
+
+	slog.Info("Restored backup", "id", b.ID, "created", b.Created.Local().Format(time.DateTime))
+	return printRestored(cmd, b)
+}
+
+func runBackupsList(cmd *cobra.Command, _ []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	store, err := backup.NewStore()
+	if err != nil {
+		return err
+	}
+
+	backups, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(backups))
+	for _, b := range backups {
+		for i, f := range b.Files {
+			id, created := b.ID, b.Created.Local().Format(time.DateTime)
+			if i > 0 {
+				id, created = "", ""
+			}
+			rows = append(rows, []string{id, created, f.Path})
+		}
+	}
+
+	return printer.Table([]string{"id", "created", "file"}, rows, backups)
+}
+
+func runBackupsRestore(cmd *cobra.Command, args []string) error {
+	store, err := backup.NewStore()
+	if err != nil {
+		return err
+	}
+	store.DryRun = configFlags.Write.DryRun
+
+	b, err := store.Restore(args[0])
+	if err != nil || dryRun {
+		return err
+	}
+
+	slog.Info("Restored backup", "id", b.ID, "created", b.Created.Local().Format(time.DateTime))
+	return printRestored(cmd, b)
+}
+
+// printRestored prints the files written by a restore
+func printRestored(cmd *cobra.Command, b *backup.Backup) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(b.Files))
+	for _, f := range b.Files {
+		rows = append(rows, []string{f.Path})
+	}
+	return printer.Table([]string{"restored"}, rows, b)
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/backup"
+	ctx "github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndo_RevertsSwitch(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+		"ctx3": "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	require.NoError(t, backup.Enable(&configFlags.Write))
+	t.Cleanup(func() { configFlags.Write = kubeconfig.UpdateOptions{} })
+
+	require.NoError(t, runContextSwitch(&cobra.Command{}, []string{"ctx2"}))
+	require.NoError(t, runContextSwitch(&cobra.Command{}, []string{"ctx3"}))
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	require.NoError(t, runBackupsList(cmd, nil))
+	assert.Contains(t, out.String(), kubeconfigPath)
+
+	require.NoError(t, runUndo(&cobra.Command{}, nil))
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", mgr.GetCurrentContext())
+
+	require.NoError(t, runUndo(&cobra.Command{}, nil))
+	mgr, err = ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+
+	assert.Error(t, runUndo(&cobra.Command{}, nil), "no backups left")
+}
+
+func TestBackupsRestore(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	require.NoError(t, backup.Enable(&configFlags.Write))
+	t.Cleanup(func() { configFlags.Write = kubeconfig.UpdateOptions{} })
+
+	require.NoError(t, runContextSwitch(&cobra.Command{}, []string{"ctx2"}))
+
+	store, err := backup.NewStore()
+	require.NoError(t, err)
+	backups, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	require.NoError(t, runBackupsRestore(&cobra.Command{}, []string{backups[0].ID}))
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+
+	assert.Error(t, runBackupsRestore(&cobra.Command{}, []string{"unknown"}))
+}
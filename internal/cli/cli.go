@@ -0,0 +1,230 @@
+// Package cli implements the kubectl-ctx and kubectl-ns commands. Both are
+// served by a single binary that picks its behavior from the name it is
+// invoked as, so kubectl-ns can be a symlink to kubectl-ctx.
+package cli
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/backup"
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/credentials"
+	"github.com/camaeel/kubectl-ctx/internal/hooks"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/picker"
+	"github.com/camaeel/kubectl-ctx/internal/utils/logging"
+	"github.com/camaeel/kubectl-ctx/internal/utils/terminal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Binary names the multi-call binary dispatches on
+const (
+	ContextBinary   = "kubectl-ctx"
+	NamespaceBinary = "kubectl-ns"
+)
+
+// Version is set by the main package from build flags
+var Version = "dev"
+
+var (
+	// outputFormat selects how results are printed to stdout
+	outputFormat string
+	// dryRun prints the kubeconfig changes instead of writing them
+	dryRun bool
+	// configFlags holds kubectl's --kubeconfig, --context, --cluster and --user
+	configFlags = kubeconfig.NewConfigFlags()
+	// expiryWarning is how long before credentials expire to warn about them
+	expiryWarning time.Duration
+	// plain selects interactively with a numbered prompt instead of the picker
+	plain bool
+)
+
+// Main runs the command selected by the binary name in args[0] and returns
+// the exit code
+func Main(args []string) int {
+	logging.SetupCLILogger()
+
+	root := NewCommand(args[0])
+	root.SetArgs(preferContext(root, args[1:]))
+
+	if err := root.Execute(); err != nil {
+		slog.Error("Error occurred:", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// NewCommand returns the root command for the binary invoked as argv0.
+// kubectl-ns is the namespace switcher; any other name is the context
+// switcher, which also provides the ctx and ns subcommands.
+func NewCommand(argv0 string) *cobra.Command {
+	name := strings.TrimSuffix(filepath.Base(argv0), ".exe")
+	if name == NamespaceBinary {
+		return newRootCommand(newNamespaceCommand(NamespaceBinary))
+	}
+
+	root := newRootCommand(newContextCommand(ContextBinary))
+	root.AddCommand(
+		newContextCommand("ctx"),
+		newNamespaceCommand("ns"),
+		newUndoCommand(),
+		newBackupsCommand(),
+		newExplainCommand(),
+		newFilesCommand(),
+		newConfigCommand(),
+		newDescribeCommand(),
+		newRenameCommand(),
+		newExportCommand(),
+		newListCommand(),
+		newPinCommand(),
+		newUnpinCommand(),
+		newCredsCommand(),
+		newKrewManifestCommand(),
+	)
+	return root
+}
+
+// preferContext keeps contexts named like a subcommand (e.g. "list")
+// reachable, as they were before the subcommands existed: when the first
+// argument names both, the arguments are rewritten to switch to the context.
+// "kubectl-ctx -- NAME" always addresses the context.
+func preferContext(root *cobra.Command, args []string) []string {
+	if slices.Contains(args, "--") {
+		return args
+	}
+	sub, _, err := root.Find(args)
+	if err != nil || sub == root || sub.Parent() != root {
+		return args
+	}
+	i := slices.IndexFunc(args, func(arg string) bool { return arg == sub.Name() || sub.HasAlias(arg) })
+	if i < 0 {
+		return args
+	}
+
+	// Parse the kubeconfig flags to look up the context where the switch would
+	flags := pflag.NewFlagSet(root.Name(), pflag.ContinueOnError)
+	flags.ParseErrorsAllowlist.UnknownFlags = true
+	flags.SetOutput(io.Discard)
+	flags.AddFlagSet(root.PersistentFlags())
+	if err := flags.Parse(args); err != nil {
+		return args
+	}
+	manager, err := context.NewManager(configFlags)
+	if err != nil || manager.ValidateContext(args[i]) != nil {
+		return args
+	}
+
+	slog.Debug("Context shadows subcommand, switching to it", "context", args[i])
+	rewritten := append(slices.Clone(args[:i]), args[i+1:]...)
+	return append(rewritten, "--", args[i])
+}
+
+// newRootCommand adds the flags and setup shared by all commands to root
+func newRootCommand(root *cobra.Command) *cobra.Command {
+	root.Version = Version
+	root.SilenceUsage = true
+	root.SilenceErrors = true
+	root.PersistentPreRunE = setup
+
+	// Ensure help flags are parsed before positional args
+	root.Flags().SetInterspersed(true)
+
+	flags := root.PersistentFlags()
+	logging.AddFlags(flags)
+	configFlags.AddFlags(flags)
+	flags.BoolVar(&dryRun, "dry-run", false, "print a unified diff of the kubeconfig changes instead of writing them")
+	flags.StringVarP(&outputFormat, "output", "o", output.FormatText, "output format: text or json")
+	flags.DurationVar(&expiryWarning, "expiry-warning", credentials.DefaultWarningWindow, "warn about credentials expiring within this duration (0 disables)")
+	flags.BoolVar(&plain, "plain", false, "select from a numbered list instead of the full-screen picker (default when TERM=dumb)")
+
+	return root
+}
+
+// rawConfigAnnotation marks commands that must run with an invalid config
+// file, which setup doesn't load for them
+const rawConfigAnnotation = "kubectl-ctx/raw-config"
+
+// setup applies the shared flags and loads the config file before any
+// command runs
+func setup(cmd *cobra.Command, _ []string) error {
+	if err := logging.ApplyFlags(); err != nil {
+		return err
+	}
+
+	configFlags.Config = nil
+	if _, raw := cmd.Annotations[rawConfigAnnotation]; !raw {
+		c, err := config.Load()
+		if err != nil {
+			return err
+		}
+		configFlags.Config = c
+	}
+
+	if dryRun {
+		// Print a diff per file instead of writing (and backing up)
+		printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+		if err != nil {
+			return err
+		}
+		configFlags.Write = kubeconfig.UpdateOptions{DryRun: kubeconfig.ReportDiffs(printer.Diff)}
+		return nil
+	}
+	configFlags.Write = kubeconfig.UpdateOptions{}
+	return backup.Enable(&configFlags.Write)
+}
+
+// reloadToolConfig re-reads the config file after a command changed it
+func reloadToolConfig() error {
+	if configFlags.Config == nil {
+		return nil // Loaded on use
+	}
+	c, err := config.Load()
+	if err != nil {
+		return err
+	}
+	configFlags.Config = c
+	return nil
+}
+
+// withHooks runs switchFn between the pre and post switch hooks. Hooks are
+// skipped in dry-run mode, as nothing is switched.
+func withHooks(event hooks.Event, switchFn func() error) error {
+	if dryRun {
+		return switchFn()
+	}
+	c, err := configFlags.ToolConfig()
+	if err != nil {
+		return err
+	}
+	if err := hooks.Run(hooks.PhasePre, c, event); err != nil {
+		return err
+	}
+	if err := switchFn(); err != nil {
+		return err
+	}
+	return hooks.Run(hooks.PhasePost, c, event)
+}
+
+// pick selects an item interactively with the full-screen picker, or with a
+// numbered prompt on cmd's stdin with --plain and on dumb terminals. The
+// page size comes from the config file.
+func pick(cmd *cobra.Command, opts picker.Options) (string, error) {
+	c, err := configFlags.ToolConfig()
+	if err != nil {
+		return "", err
+	}
+	opts.PageSize = c.Picker.PageSize
+
+	if plain || terminal.IsDumb() {
+		return picker.Plain(opts, cmd.InOrStdin(), cmd.ErrOrStderr())
+	}
+	return picker.Run(opts)
+}
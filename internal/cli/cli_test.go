@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/krew"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+)
+
+// TestMain keeps the tool's state, config and cache of the user out of the
+// tests, as switches record context usage
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunIsolated(m))
+}
+
+func TestNewCommand_Dispatch(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{"test-ctx": "web"})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	tests := []struct {
+		name  string
+		argv0 string
+		args  []string
+		want  string
+	}{
+		{name: "kubectl-ctx", argv0: "/usr/local/bin/kubectl-ctx", args: []string{"--current"}, want: "test-ctx\n"},
+		{name: "kubectl-ns symlink", argv0: "/usr/local/bin/kubectl-ns", args: []string{"--current"}, want: "web\n"},
+		{name: "windows", argv0: `kubectl-ns.exe`, args: []string{"--current"}, want: "web\n"},
+		{name: "ctx subcommand", argv0: "kubectl-ctx", args: []string{"ctx", "--current"}, want: "test-ctx\n"},
+		{name: "ns subcommand", argv0: "kubectl-ctx", args: []string{"ns", "--current"}, want: "web\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := NewCommand(tt.argv0)
+			cmd.SetOut(&out)
+			cmd.SetArgs(tt.args)
+
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestPreferContext(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	kubeconfigPath := testutil.CreateKubeconfig(t, "a", map[string]string{"a": "", "list": ""})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		root := NewCommand(ContextBinary)
+		root.SetOut(&out)
+		root.SetArgs(preferContext(root, args))
+		require.NoError(t, root.Execute())
+		return out.String()
+	}
+	current := func() (string, string) {
+		t.Helper()
+		config, err := clientcmd.LoadFromFile(kubeconfigPath)
+		require.NoError(t, err)
+		return config.CurrentContext, config.Contexts[config.CurrentContext].Namespace
+	}
+
+	// The context named like the list subcommand is switched to
+	assert.Equal(t, []string{"-n", "web", "--", "list"}, preferContext(NewCommand(ContextBinary), []string{"list", "-n", "web"}))
+	run("list", "-n", "web")
+	gotContext, gotNamespace := current()
+	assert.Equal(t, "list", gotContext)
+	assert.Equal(t, "web", gotNamespace)
+
+	run("a")
+	run("--", "list")
+	gotContext, _ = current()
+	assert.Equal(t, "list", gotContext)
+
+	// Subcommands without a context of their name are unaffected
+	assert.Equal(t, []string{"files", "export"}, preferContext(NewCommand(ContextBinary), []string{"files", "export"}))
+	assert.Equal(t, []string{"list"}, preferContext(NewCommand(NamespaceBinary), []string{"list"}))
+}
+
+func TestNewCommand_NamespaceHasNoSubcommands(t *testing.T) {
+	cmd := NewCommand("kubectl-ns")
+	assert.Equal(t, "kubectl-ns", cmd.Name())
+	assert.False(t, cmd.HasSubCommands())
+}
+
+func TestKrewManifest(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	var sums bytes.Buffer
+	for _, p := range krew.Platforms {
+		sums.WriteString("abc123  ./" + krew.ArchiveName(p) + "\n")
+	}
+	checksums := filepath.Join(t.TempDir(), "SHA256SUMS")
+	require.NoError(t, os.WriteFile(checksums, sums.Bytes(), 0600))
+
+	var out bytes.Buffer
+	cmd := NewCommand("kubectl-ctx")
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"krew-manifest", "ns", "--version", "v1.2.3", "--checksums", checksums})
+	require.NoError(t, cmd.Execute())
+
+	var manifest krew.Manifest
+	require.NoError(t, yaml.Unmarshal(out.Bytes(), &manifest))
+	assert.Equal(t, "ns", manifest.Metadata.Name)
+	assert.Equal(t, "v1.2.3", manifest.Spec.Version)
+	require.Len(t, manifest.Spec.Platforms, len(krew.Platforms))
+	assert.Equal(t, "kubectl-ns", manifest.Spec.Platforms[0].Bin)
+
+	cmd.SetArgs([]string{"krew-manifest", "unknown", "--checksums", checksums})
+	assert.Error(t, cmd.Execute())
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// newConfigCommand returns the config command with its subcommands
+func newConfigCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and edit the configuration file",
+		Long: `View and edit the configuration file both tools load, at
+$XDG_CONFIG_HOME/kubectl-ctx/config.yaml (default ~/.config/kubectl-ctx).
+
+The file is validated against the schema of its version whenever it is
+loaded; errors name the line, column and field at fault. KUBECTL_CTX_SORT,
+KUBECTL_CTX_PAGE_SIZE, KUBECTL_CTX_PROTECTED (comma separated) and
+KUBECTL_CTX_CLUSTER_INFO_TTL override the settings of the file.`,
+		Example: `  # Print the configuration in effect
+  kubectl-ctx config view
+
+  # Edit the configuration file with $KUBE_EDITOR or $EDITOR
+  kubectl-ctx config edit`,
+		Args: cobra.NoArgs,
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "view",
+		Short: "Print the configuration with the environment overrides applied",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigView,
+	}, &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the configuration file, saving it only if it is valid",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigEdit,
+		// An invalid file must still be editable
+		Annotations: map[string]string{rawConfigAnnotation: "true"},
+	})
+
+	return configCmd
+}
+
+func runConfigView(cmd *cobra.Command, _ []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	c, err := configFlags.ToolConfig()
+	if err != nil {
+		return err
+	}
+	c.Version = config.Version
+
+	return printer.Object(c, func(w io.Writer) error {
+		content, err := yaml.Marshal(c)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	})
+}
+
+func runConfigEdit(_ *cobra.Command, _ []string) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	content := original
+	if original == nil {
+		content = fmt.Appendf(nil, "version: %d\n", config.Version)
+	}
+
+	// Edit a copy next to the file, so it can be renamed over it once valid
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create config copy: %w", err)
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to create config copy: %w", err)
+	}
+
+	if err := runEditor(tmp.Name()); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("failed to read edited config: %w", err)
+	}
+	if bytes.Equal(edited, content) {
+		_ = os.Remove(tmp.Name())
+		slog.Info("Config unchanged", "file", path)
+		return nil
+	}
+	if _, err := config.LoadFile(tmp.Name()); err != nil {
+		return fmt.Errorf("config not saved, the edit is kept in %s: %w", tmp.Name(), err)
+	}
+
+	// Other commands may have updated the file while it was being edited
+	if err := config.Replace(original, edited); err != nil {
+		return fmt.Errorf("config not saved, the edit is kept in %s: %w", tmp.Name(), err)
+	}
+	_ = os.Remove(tmp.Name())
+	slog.Info("Saved config", "file", path)
+	return nil
+}
+
+// runEditor opens file in $KUBE_EDITOR or $EDITOR, like kubectl edit
+func runEditor(file string) error {
+	editor := os.Getenv("KUBE_EDITOR")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], file)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+	return nil
+}
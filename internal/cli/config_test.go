@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setEditor makes a shell script writing content the editor
+func setEditor(t *testing.T, content string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the editor script needs a POSIX shell")
+	}
+	script := filepath.Join(t.TempDir(), "editor")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ncat > \"$1\" <<'EOF'\n"+content+"EOF\n"), 0700))
+	t.Setenv("KUBE_EDITOR", script)
+}
+
+func TestRunConfigView(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	out, err := execute(t, ContextBinary, "config", "view")
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1\n", out)
+
+	require.NoError(t, config.Update(func(c *config.Config) error {
+		c.Sort = "alpha"
+		c.Protected = []string{"prod-*"}
+		return nil
+	}))
+	t.Setenv(config.EnvSort, "recent")
+
+	out, err = execute(t, ContextBinary, "config", "view")
+	require.NoError(t, err)
+	assert.Equal(t, "protected:\n- prod-*\nsort: recent\nversion: 1\n", out)
+
+	out, err = execute(t, ContextBinary, "config", "view", "-o", "json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version": 1, "sort": "recent", "protected": ["prod-*"]}`, out)
+}
+
+func TestRunConfigEdit(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := config.Path()
+	require.NoError(t, err)
+
+	setEditor(t, "version: 1\nsort: alpha\n")
+	_, err = execute(t, ContextBinary, "config", "edit")
+	require.NoError(t, err)
+	c, err := config.LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", c.Sort)
+
+	// An invalid edit is kept aside, leaving the file unchanged
+	setEditor(t, "version: 1\nsort: newest\n")
+	_, err = execute(t, ContextBinary, "config", "edit")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config not saved")
+	assert.Contains(t, err.Error(), `:2:7: sort: invalid sort order "newest"`)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1\nsort: alpha\n", string(content))
+
+	kept, err := filepath.Glob(filepath.Join(filepath.Dir(path), "config-*.yaml"))
+	require.NoError(t, err)
+	assert.Len(t, kept, 1)
+}
+
+func TestRunConfigEdit_ConcurrentChange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the editor script needs a POSIX shell")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	require.NoError(t, config.Update(func(c *config.Config) error {
+		c.Sort = "alpha"
+		return nil
+	}))
+	path, err := config.Path()
+	require.NoError(t, err)
+
+	// Another command updates the file while the editor is open
+	script := filepath.Join(t.TempDir(), "editor")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"+
+		"printf 'version: 1\\nsort: recent\\n' > \"$1\"\n"+
+		"printf 'version: 1\\nsort: cluster\\n' > '"+path+"'\n"), 0700))
+	t.Setenv("KUBE_EDITOR", script)
+
+	_, err = execute(t, ContextBinary, "config", "edit")
+	require.ErrorIs(t, err, config.ErrChanged)
+	assert.Contains(t, err.Error(), "the edit is kept in")
+	c, err := config.LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "cluster", c.Sort)
+	assert.NoFileExists(t, path+".lock")
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/credentials"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// newCredsCommand returns the command listing credential expiry
+func newCredsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "creds [CONTEXT_NAME]",
+		Short: "Show when the credentials of contexts expire",
+		Long: `Show the expiry of the credentials of all contexts, or of one context:
+client certificates (inline or file), JWT bearer tokens (exp claim), oidc
+auth-provider id-tokens and tokens cached by known exec plugins
+(gke-gcloud-auth-plugin). Credentials expiring within --expiry-warning are
+marked. Switching to their context warns about client certificates and
+tokens, but not about the credentials exec plugins and auth-providers renew.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runCreds,
+	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "show only the contexts whose tags match, e.g. env=prod,team!=infra")
+
+	return cmd
+}
+
+func runCreds(cmd *cobra.Command, args []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	manager, err := context.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+
+	names := manager.ListContexts()
+	if selector != "" {
+		if names, err = manager.SelectContexts(names, selector); err != nil {
+			return err
+		}
+	}
+	if len(args) > 0 {
+		if err := manager.ValidateContext(args[0]); err != nil {
+			return err
+		}
+		names = args[:1]
+	}
+
+	now := time.Now()
+	expiries := []credentials.Expiry{}
+	var rows [][]string
+	for _, name := range names {
+		for _, e := range manager.CredentialExpiries(name) {
+			expiries = append(expiries, e)
+
+			expires := "-"
+			if e.Expiry != nil {
+				expires = e.Expiry.Local().Format(time.DateTime)
+			}
+			rows = append(rows, []string{e.Context, e.User, e.Kind, e.Source, expires, expiryStatus(e, now)})
+		}
+	}
+
+	return printer.Table([]string{"context", "user", "credential", "source", "expires", "status"}, rows, expiries)
+}
+
+// expiryStatus describes an expiry relative to now and the warning window
+func expiryStatus(e credentials.Expiry, now time.Time) string {
+	switch {
+	case e.Error != "":
+		return "error: " + e.Error
+	case e.Expiry == nil:
+		return "unknown"
+	case e.Expiry.Before(now):
+		return "expired"
+	case e.ExpiresWithin(now, expiryWarning):
+		return "expires in " + formatDuration(e.Expiry.Sub(now))
+	default:
+		return "valid"
+	}
+}
+
+// warnExpiring logs a warning for each credential expiring within the
+// warning window. Short-lived credentials renewed by exec plugins and
+// auth-providers would warn on every switch, so they are left out.
+func warnExpiring(expiries []credentials.Expiry) {
+	now := time.Now()
+	for _, e := range expiries {
+		if expiryWarning <= 0 || e.Refreshes() || !e.ExpiresWithin(now, expiryWarning) {
+			continue
+		}
+		attrs := []any{"context", e.Context, "user", e.User, "credential", e.Kind, "expiry", e.Expiry.Local().Format(time.DateTime)}
+		if e.Expiry.Before(now) {
+			slog.Warn("Credential has expired", attrs...)
+		} else {
+			slog.Warn("Credential expires in "+formatDuration(e.Expiry.Sub(now)), attrs...)
+		}
+	}
+}
+
+// formatDuration rounds a duration to days, hours or minutes
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/credentials"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestRunCreds(t *testing.T) {
+	config := api.NewConfig()
+	config.Clusters["test"] = &api.Cluster{Server: "https://localhost:6443"}
+	config.AuthInfos["cert"] = &api.AuthInfo{ClientCertificateData: testutil.NewCertificate(t, time.Now().Add(72*time.Hour))}
+	config.AuthInfos["token"] = &api.AuthInfo{Token: testutil.NewJWT(time.Now().Add(-time.Hour))}
+	config.AuthInfos["static"] = &api.AuthInfo{Token: "opaque"}
+	config.Contexts["cert"] = &api.Context{Cluster: "test", AuthInfo: "cert"}
+	config.Contexts["token"] = &api.Context{Cluster: "test", AuthInfo: "token"}
+	config.Contexts["static"] = &api.Context{Cluster: "test", AuthInfo: "static"}
+	config.CurrentContext = "cert"
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, clientcmd.WriteToFile(*config, kubeconfigPath))
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	expiryWarning = credentials.DefaultWarningWindow
+	t.Cleanup(func() { expiryWarning = 0 })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runCreds(cmd, nil))
+	assert.Regexp(t, `cert\s+cert\s+client-certificate\s+inline\s+\S+ \S+\s+expires in 2d`, out.String())
+	assert.Regexp(t, `static\s+static\s+token\s+inline\s+-\s+unknown`, out.String())
+	assert.Regexp(t, `token\s+token\s+token\s+inline\s+\S+ \S+\s+expired`, out.String())
+
+	outputFormat = output.FormatJSON
+	t.Cleanup(func() { outputFormat = output.FormatText })
+
+	out.Reset()
+	require.NoError(t, runCreds(cmd, []string{"token"}))
+	var expiries []credentials.Expiry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &expiries))
+	require.Len(t, expiries, 1)
+	assert.Equal(t, "token", expiries[0].Context)
+	assert.Equal(t, credentials.KindToken, expiries[0].Kind)
+	assert.NotNil(t, expiries[0].Expiry)
+
+	assert.Error(t, runCreds(cmd, []string{"unknown"}))
+}
@@ -0,0 +1,446 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/clusterinfo"
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/hooks"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/picker"
+	"github.com/camaeel/kubectl-ctx/internal/pins"
+	"github.com/camaeel/kubectl-ctx/internal/usage"
+	"github.com/camaeel/kubectl-ctx/internal/utils/logging"
+	"github.com/camaeel/kubectl-ctx/internal/utils/terminal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// namespaceFlag sets the namespace of the target context in the same write
+	namespaceFlag string
+	// pickNamespace adds a namespace selection step to interactive mode
+	pickNamespace bool
+	// showCurrentContext prints the current context and exits
+	showCurrentContext bool
+	// login runs the credential plugin of the target context after switching
+	login bool
+	// showInfo adds server version and node count to the context selector
+	showInfo bool
+	// sortOrder orders the context list and selector, overriding the config
+	sortOrder string
+	// selector filters the context list and selector by tags
+	selector string
+)
+
+// newContextCommand returns the context switcher named use
+func newContextCommand(use string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use + " [[FILE:]CONTEXT_NAME[/NAMESPACE]]",
+		Short: "Switch between Kubernetes contexts",
+		Long: `kubectl-ctx switches between the contexts of the kubeconfig files.
+
+With a context name, it switches to that context; CONTEXT_NAME/NAMESPACE or
+--namespace also sets the namespace of the context in the same kubeconfig
+write. Without one, it shows the current context and selects the next one
+interactively, from a full-screen picker or, with --plain (the default when
+TERM=dumb), a numbered list answered with a number, name or prefix. When stdin
+or stdout is not a terminal, it prints the contexts instead, marking the
+current one with "*". Results go to stdout and diagnostics to stderr.
+
+Like kubectl, it merges the files listed in KUBECONFIG, and --kubeconfig,
+--context, --cluster and --user select the file, context and connection to
+use. When several files define the same context name, FILE:CONTEXT_NAME (the
+file's path or base name) addresses the definition in a given file. Every
+switch backs up the files it modifies, so that "undo" can revert it.
+
+Invoked as kubectl-ns, e.g. through a symlink, the binary switches namespaces
+instead; the ctx and ns subcommands provide both tools.`,
+		Example: `  # Show current context and select interactively
+  kubectl-ctx
+
+  # Switch to a specific context
+  kubectl-ctx my-context
+
+  # Switch context and namespace at once
+  kubectl-ctx prod/payments
+  kubectl-ctx prod -n payments
+
+  # Switch to the context as defined in a specific KUBECONFIG file
+  kubectl-ctx file1.yaml:shared-context
+
+  # Select from a numbered list (the default when TERM=dumb)
+  kubectl-ctx --plain
+
+  # Select context and then namespace interactively
+  kubectl-ctx --pick-namespace
+
+  # Select among the production contexts not owned by the infra team
+  kubectl-ctx -l env=prod,team!=infra
+
+  # Print the current context for use in scripts
+  CURRENT=$(kubectl-ctx --current)
+
+  # Switch and log in right away (e.g. EKS, GKE or OIDC contexts)
+  kubectl-ctx prod --login
+
+  # Switch and report the result as JSON
+  kubectl-ctx prod -o json
+
+  # Switch within a specific kubeconfig file
+  kubectl-ctx --kubeconfig ~/.kube/other.yaml my-context
+
+  # Revert the last switch, or restore an older backup
+  kubectl-ctx undo
+  kubectl-ctx backups list
+
+  # Switch namespaces in the current context
+  kubectl-ctx ns payments`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runContextSwitch,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeContexts(nil)(cmd, args, toComplete)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespaceFlag, "namespace", "n", "", "namespace to set on the target context")
+	cmd.Flags().BoolVarP(&pickNamespace, "pick-namespace", "N", false, "select a namespace after selecting a context interactively")
+	cmd.Flags().BoolVarP(&showCurrentContext, "current", "c", false, "print the current context")
+	cmd.Flags().BoolVar(&showInfo, "info", false, "show the server version and node count of each context in the interactive selector, except for contexts using credential plugins")
+	cmd.Flags().StringVar(&sortOrder, "sort", "", "order of the context list and selector: frecency, recent, alpha or cluster (default from the config file, else frecency on a terminal and alpha otherwise)")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "filter the context list, selector and completions by tags, set in the kubectl-ctx/tags context extension or the config file, e.g. env=prod,team!=infra")
+	cmd.Flags().BoolVar(&login, "login", false, "run the exec or auth-provider credential plugin of the target context after switching")
+
+	return cmd
+}
+
+func runContextSwitch(cmd *cobra.Command, args []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	// Create context manager
+	manager, err := context.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+
+	currentContext := manager.GetCurrentContext()
+
+	if showCurrentContext {
+		if currentContext == "" {
+			return fmt.Errorf("no current context set")
+		}
+		return printer.Current(currentContext)
+	}
+
+	manager.WarnShadowed()
+
+	var targetContext, targetNamespace string
+
+	// If argument provided, use it; otherwise show interactive selection
+	if len(args) > 0 {
+		targetContext, targetNamespace, err = manager.ResolveTarget(args[0])
+		if err != nil {
+			return err
+		}
+	} else {
+		// Get available contexts, most likely targets first
+		contexts, err := sortedContexts(manager, defaultSortOrder())
+		if err != nil {
+			return err
+		}
+
+		if !terminal.IsInteractive() {
+			// Not a terminal: print the list for scripts instead of prompting
+			return printer.List(contexts, currentContext)
+		}
+
+		// Show current context
+		if currentContext == "" {
+			slog.Warn("No current context set")
+		} else {
+			slog.Info("Current context", "context", currentContext)
+		}
+
+		if len(contexts) == 0 && selector != "" {
+			return fmt.Errorf("no contexts match selector %q", selector)
+		}
+
+		// Interactive selection
+		targetContext, err = selectContext(cmd, manager, contexts, currentContext)
+		if err != nil {
+			return err
+		}
+
+		if pickNamespace && namespaceFlag == "" {
+			targetNamespace, err = selectNamespace(cmd, manager, targetContext)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if namespaceFlag != "" {
+		if targetNamespace != "" && targetNamespace != namespaceFlag {
+			return fmt.Errorf("conflicting namespaces %q and %q", targetNamespace, namespaceFlag)
+		}
+		targetNamespace = namespaceFlag
+	}
+
+	// --context only overrides reads; a switch replaces the current context
+	// of the kubeconfig, which may also have been renamed in the picker
+	currentContext = manager.ConfiguredContext()
+
+	result := output.SwitchResult{
+		Kind:      "context",
+		From:      currentContext,
+		To:        targetContext,
+		Namespace: targetNamespace,
+		File:      manager.CurrentContextFile(),
+	}
+
+	if targetNamespace != "" {
+		result.Changed = targetContext != currentContext || targetNamespace != manager.GetContextNamespace(targetContext)
+	} else {
+		result.Changed = targetContext != currentContext
+	}
+
+	if result.To != result.From {
+		protected, err := manager.IsProtected(targetContext)
+		if err != nil {
+			return err
+		}
+		if protected {
+			slog.Warn("Switching to a protected context", "context", targetContext)
+		}
+	}
+
+	// Don't switch if already on target context (and namespace)
+	if !result.Changed {
+		recordUsage(targetContext)
+		return printSwitch(printer, manager, result)
+	}
+
+	if err := configFlags.CheckSwitch(manager.ContextFile(targetContext)); err != nil {
+		return fmt.Errorf("can't switch to context %q: %w", targetContext, err)
+	}
+
+	event := hooks.Event{
+		Kind:         "context",
+		OldContext:   currentContext,
+		NewContext:   targetContext,
+		OldNamespace: manager.GetContextNamespace(currentContext),
+		NewNamespace: manager.GetContextNamespace(targetContext),
+		OldServer:    manager.GetContextServer(currentContext),
+		NewServer:    manager.GetContextServer(targetContext),
+	}
+	if targetNamespace != "" {
+		event.NewNamespace = targetNamespace
+	}
+
+	err = withHooks(event, func() error {
+		if targetNamespace != "" {
+			return manager.SwitchContextAndNamespace(targetContext, targetNamespace)
+		}
+		return manager.SwitchContext(targetContext)
+	})
+	if err != nil {
+		return err
+	}
+
+	recordUsage(targetContext)
+	result.DryRun = dryRun
+	return printSwitch(printer, manager, result)
+}
+
+// printSwitch prints the switch result, after pre-warming the credentials of
+// the target context with --login so the next kubectl command doesn't stall
+func printSwitch(printer *output.Printer, manager *context.Manager, result output.SwitchResult) error {
+	warnExpiring(manager.CredentialExpiries(result.To))
+
+	if !login || dryRun {
+		return printer.Switch(result)
+	}
+
+	var loginErr error
+	result.Login, loginErr = manager.Login(result.To)
+	if err := printer.Switch(result); err != nil {
+		return err
+	}
+	if loginErr != nil {
+		return fmt.Errorf("login to context %q failed: %w", result.To, loginErr)
+	}
+	return nil
+}
+
+// sortedContexts returns the contexts matching --selector in the order
+// selected by --sort, KUBECTL_CTX_SORT or the config file, defaulting to
+// defaultOrder
+func sortedContexts(manager *context.Manager, defaultOrder string) ([]string, error) {
+	order := sortOrder
+	if order == "" {
+		c, err := configFlags.ToolConfig()
+		if err != nil {
+			return nil, err
+		}
+		if order = c.Sort; order == "" {
+			order = defaultOrder
+		}
+	}
+
+	u, err := usage.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := manager.ListContexts()
+	if selector != "" {
+		if contexts, err = manager.SelectContexts(contexts, selector); err != nil {
+			return nil, err
+		}
+	}
+	if err := manager.SortContexts(contexts, order, u); err != nil {
+		return nil, err
+	}
+	return contexts, nil
+}
+
+// defaultSortOrder returns the order used without --sort or a sort setting:
+// the most likely targets first on a terminal, a stable order by name for
+// scripts reading stdout
+func defaultSortOrder() string {
+	if terminal.IsInteractive() {
+		return config.SortFrecency
+	}
+	return config.SortAlpha
+}
+
+// recordUsage counts a switch to a context for frecency sorting. Failures
+// only affect the sort order, so they don't fail the switch.
+func recordUsage(name string) {
+	if dryRun {
+		return
+	}
+	if err := usage.Record(name, time.Now()); err != nil {
+		slog.Warn("Failed to record context usage", "error", err)
+	}
+}
+
+// startClusterInfo fetches the cluster info of contexts in the background.
+// The selector renders the info available on each redraw, so it stays
+// responsive while the info streams in.
+func startClusterInfo(manager *context.Manager, contexts []string) (*clusterinfo.Collector, error) {
+	collector, err := clusterinfo.NewCollector()
+	if err != nil {
+		return nil, err
+	}
+	c, err := configFlags.ToolConfig()
+	if err != nil {
+		return nil, err
+	}
+	if c.Cache.ClusterInfo != nil {
+		collector.TTL = c.Cache.ClusterInfo.Duration
+	}
+
+	servers := make(map[string]string, len(contexts))
+	for _, name := range contexts {
+		servers[name] = manager.GetContextServer(name)
+	}
+	collector.Start(servers, manager.FetchClusterInfo)
+	return collector, nil
+}
+
+// selectContext interactively selects a context, previewing the description
+// of the highlighted one. Contexts can be renamed and deleted in the picker.
+func selectContext(cmd *cobra.Command, manager *context.Manager, contexts []string, currentContext string) (string, error) {
+	p, err := pins.Load()
+	if err != nil {
+		return "", err
+	}
+
+	opts := picker.Options{
+		Kind:    "context",
+		Items:   contexts,
+		Current: currentContext,
+		Pinned:  p.Contexts,
+		Preview: func(name string) string {
+			d, err := manager.Describe(name)
+			if err != nil {
+				return err.Error()
+			}
+			var b strings.Builder
+			if err := renderDescription(&b, d); err != nil {
+				return err.Error()
+			}
+			return b.String()
+		},
+		Rename: func(oldName, newName string) error {
+			return renameContext(manager, oldName, newName)
+		},
+		Delete: func(name string) error {
+			if err := manager.DeleteContext(name); err != nil {
+				return err
+			}
+			err := pins.Update(func(p *pins.Pins) error {
+				p.UnpinContext(name)
+				return nil
+			})
+			if err != nil {
+				slog.Debug("Failed to unpin deleted context", "error", err)
+			}
+			return nil
+		},
+		Color: logging.ColorEnabled(os.Stderr),
+		Style: func(name string) string {
+			color, err := manager.Color(name)
+			if err != nil {
+				slog.Debug("Failed to color context", "context", name, "error", err)
+			}
+			return color
+		},
+	}
+
+	if showInfo {
+		collector, err := startClusterInfo(manager, contexts)
+		if err != nil {
+			return "", err
+		}
+		defer func() {
+			if err := collector.Stop(); err != nil {
+				slog.Debug("Failed to save cluster info", "error", err)
+			}
+		}()
+		opts.Describe = collector.Describe
+		if plain || terminal.IsDumb() {
+			// The numbered list is printed once, so it waits for the info
+			collector.Wait()
+		}
+	}
+
+	return pick(cmd, opts)
+}
+
+// selectNamespace interactively selects a namespace of the given context.
+// An empty result keeps the namespace of the context unchanged.
+func selectNamespace(cmd *cobra.Command, manager *context.Manager, contextName string) (string, error) {
+	namespaces, err := manager.GetNamespaces(contextName)
+	if err != nil {
+		slog.Warn("Failed to fetch namespaces, keeping current namespace", "context", contextName, "error", err)
+		return "", nil
+	}
+
+	p, err := pins.Load()
+	if err != nil {
+		return "", err
+	}
+	return pickNamespaceFrom(cmd, namespaces, manager.GetContextNamespace(contextName), p.NamespacesOf(contextName))
+}
@@ -0,0 +1,698 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	ctx "github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/hooks"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/camaeel/kubectl-ctx/internal/utils/terminal"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestRunContextSwitch_WithArgument(t *testing.T) {
+	// Create test kubeconfig
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+		"ctx3": "",
+	})
+
+	// Set KUBECONFIG environment variable (automatically restored after test)
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	// Create a new command with args
+	cmd := &cobra.Command{}
+	args := []string{"ctx2"}
+
+	// Run the switch
+	err := runContextSwitch(cmd, args)
+	require.NoError(t, err)
+
+	// Verify the context was changed
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", mgr.GetCurrentContext())
+}
+
+func TestRunContextSwitch_AlreadyOnTargetContext(t *testing.T) {
+	// Create test kubeconfig with context already set
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	// Try to switch to the same context
+	cmd := &cobra.Command{}
+	args := []string{"ctx1"}
+
+	err := runContextSwitch(cmd, args)
+	require.NoError(t, err)
+
+	// Verify the context is still the same
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+}
+
+func TestRunContextSwitch_InvalidContext(t *testing.T) {
+	// Create test kubeconfig
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	cmd := &cobra.Command{}
+	args := []string{"nonexistent-context"}
+
+	err := runContextSwitch(cmd, args)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestRunContextSwitch_InvalidKubeconfig(t *testing.T) {
+	// Set KUBECONFIG to non-existent file
+	tmpDir := t.TempDir()
+	t.Setenv("KUBECONFIG", filepath.Join(tmpDir, "nonexistent"))
+
+	cmd := &cobra.Command{}
+	args := []string{"some-context"}
+
+	err := runContextSwitch(cmd, args)
+	assert.Error(t, err)
+}
+
+func TestRunContextSwitch_NoCurrentContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubeconfigPath := filepath.Join(tmpDir, "config")
+
+	// Create kubeconfig without current-context
+	content := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://localhost:6443
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: ctx1
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: ctx2
+users:
+- name: test-user
+  user: {}
+`
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(content), 0600))
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	cmd := &cobra.Command{}
+	args := []string{"ctx1"}
+
+	// Should succeed even without current context
+	err := runContextSwitch(cmd, args)
+	require.NoError(t, err)
+
+	// Verify the context was set
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+}
+
+func TestRunContextSwitch_MultipleContextSwitches(t *testing.T) {
+	// Create test kubeconfig
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+		"ctx3": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	cmd := &cobra.Command{}
+
+	// Switch to ctx2
+	err := runContextSwitch(cmd, []string{"ctx2"})
+	require.NoError(t, err)
+
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", mgr.GetCurrentContext())
+
+	// Switch to ctx3
+	err = runContextSwitch(cmd, []string{"ctx3"})
+	require.NoError(t, err)
+
+	mgr, err = ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx3", mgr.GetCurrentContext())
+
+	// Switch back to ctx1
+	err = runContextSwitch(cmd, []string{"ctx1"})
+	require.NoError(t, err)
+
+	mgr, err = ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+}
+
+func TestRunContextSwitch_ListContexts(t *testing.T) {
+	// Create test kubeconfig
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+		"ctx3": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	// Verify we can list all contexts
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+
+	contexts := mgr.ListContexts()
+	assert.ElementsMatch(t, []string{"ctx1", "ctx2", "ctx3"}, contexts)
+}
+
+func TestRunContextSwitch_MultipleKubeconfigFiles(t *testing.T) {
+	// Create two kubeconfig files
+	kubeconfig1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+	})
+
+	kubeconfig2 := testutil.CreateKubeconfig(t, "ctx3", map[string]string{
+		"ctx3": "",
+		"ctx4": "",
+	})
+
+	// Set KUBECONFIG with multiple files (colon-separated on Unix)
+	t.Setenv("KUBECONFIG", kubeconfig1+":"+kubeconfig2)
+
+	// Verify we can list contexts from both files
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+
+	contexts := mgr.ListContexts()
+	assert.ElementsMatch(t, []string{"ctx1", "ctx2", "ctx3", "ctx4"}, contexts)
+
+	// Verify current context from first file
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+
+	// Switch to context from second file
+	cmd := &cobra.Command{}
+	err = runContextSwitch(cmd, []string{"ctx3"})
+	require.NoError(t, err)
+
+	// Verify the switch
+	mgr, err = ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx3", mgr.GetCurrentContext())
+}
+
+func TestRunContextSwitch_ContextAndNamespace(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "old-ns",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	err := runContextSwitch(&cobra.Command{}, []string{"ctx2/payments"})
+	require.NoError(t, err)
+
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", mgr.GetCurrentContext())
+	assert.Equal(t, "payments", mgr.GetContextNamespace("ctx2"))
+}
+
+func TestRunContextSwitch_NamespaceFlag(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	namespaceFlag = "payments"
+	t.Cleanup(func() { namespaceFlag = "" })
+
+	err := runContextSwitch(&cobra.Command{}, []string{"ctx2"})
+	require.NoError(t, err)
+
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", mgr.GetCurrentContext())
+	assert.Equal(t, "payments", mgr.GetContextNamespace("ctx2"))
+
+	// Conflicting namespaces in the argument and the flag are rejected
+	err = runContextSwitch(&cobra.Command{}, []string{"ctx1/other"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "conflicting namespaces")
+}
+
+func TestRunContextSwitch_NonInteractiveListsContexts(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx2", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+		"ctx3": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	err := runContextSwitch(cmd, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "  ctx1\n* ctx2\n  ctx3\n", out.String())
+}
+
+func TestRunContextSwitch_JSONOutput(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	outputFormat = output.FormatJSON
+	t.Cleanup(func() { outputFormat = output.FormatText })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	err := runContextSwitch(cmd, []string{"ctx2"})
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "ctx1", result["from"])
+	assert.Equal(t, "ctx2", result["to"])
+	assert.Equal(t, kubeconfigPath, result["file"])
+	assert.Equal(t, true, result["changed"])
+}
+
+func TestRunContextSwitch_ShowCurrent(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx2", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	showCurrentContext = true
+	t.Cleanup(func() { showCurrentContext = false })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	err := runContextSwitch(cmd, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2\n", out.String())
+}
+
+func TestRunContextSwitch_DryRun(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	printer, err := output.New(&out, output.FormatText)
+	require.NoError(t, err)
+	configFlags.Write.DryRun = kubeconfig.ReportDiffs(printer.Diff)
+	dryRun = true
+	t.Cleanup(func() {
+		configFlags.Write = kubeconfig.UpdateOptions{}
+		dryRun = false
+	})
+
+	err = runContextSwitch(cmd, []string{"ctx2/payments"})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "--- a"+kubeconfigPath)
+	assert.Contains(t, out.String(), "+current-context: ctx2")
+	assert.Contains(t, out.String(), "+    namespace: payments")
+	assert.Contains(t, out.String(), `Would switch to context "ctx2"`)
+
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+}
+
+func TestRunContextSwitch_FileRef(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	kubeconfig1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1":   "",
+		"shared": "",
+	})
+	kubeconfig2 := testutil.CreateKubeconfigWithServer(t, "https://second:6443", "ctx2", map[string]string{
+		"ctx2":   "",
+		"shared": "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfig1+":"+kubeconfig2)
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+
+	require.NoError(t, runContextSwitch(cmd, []string{kubeconfig1 + ":shared/web"}))
+
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "shared", mgr.GetCurrentContext())
+	assert.Equal(t, "web", mgr.GetContextNamespace("shared"))
+
+	// kubectl can't select the shadowed definition, it must be renamed first
+	before, err := os.ReadFile(kubeconfig2)
+	require.NoError(t, err)
+	err = runContextSwitch(cmd, []string{kubeconfig2 + ":shared"})
+	require.ErrorContains(t, err, `rename it first with "rename `+kubeconfig2+`:shared shared@config"`)
+	after, err := os.ReadFile(kubeconfig2)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+
+	require.NoError(t, runRename(cmd, []string{kubeconfig2 + ":shared", "shared@config"}))
+	require.NoError(t, runContextSwitch(cmd, []string{"shared@config"}))
+
+	mgr, err = ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "shared@config", mgr.GetCurrentContext())
+	assert.Equal(t, "https://second:6443", mgr.GetContextServer("shared@config"))
+	assert.Equal(t, "https://localhost:6443", mgr.GetContextServer("shared"))
+	assert.Equal(t, "https://localhost:6443", mgr.GetContextServer("ctx2"))
+}
+
+func TestRunRenameAndExport_FileRef(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	kubeconfig1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"shared": ""})
+	kubeconfig2 := testutil.CreateKubeconfigWithServer(t, "https://second:6443", "ctx2", map[string]string{"shared": ""})
+	t.Setenv("KUBECONFIG", kubeconfig1+":"+kubeconfig2)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	// Export works from the named file, even when it is shadowed
+	require.NoError(t, runExport(cmd, []string{kubeconfig2 + ":shared"}))
+	exported, err := clientcmd.Load(out.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "shared", exported.CurrentContext)
+	assert.Len(t, exported.Contexts, 1)
+	assert.Equal(t, "https://second:6443", exported.Clusters["test-cluster"].Server)
+
+	require.NoError(t, runRename(cmd, []string{kubeconfig2 + ":shared", "shared-2"}))
+
+	config, err := clientcmd.LoadFromFile(kubeconfig2)
+	require.NoError(t, err)
+	assert.NotContains(t, config.Contexts, "shared")
+	assert.Equal(t, "shared-2", config.Contexts["shared-2"].Cluster)
+	config, err = clientcmd.LoadFromFile(kubeconfig1)
+	require.NoError(t, err)
+	assert.Contains(t, config.Contexts, "shared")
+
+	err = runRename(cmd, []string{kubeconfig2 + ":shared", "other"})
+	assert.ErrorContains(t, err, "not found in")
+}
+
+func TestRunContextSwitch_ConfigFlags(t *testing.T) {
+	defaultPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": ""})
+	explicitPath := testutil.CreateKubeconfig(t, "ctx2", map[string]string{"ctx2": "", "ctx3": ""})
+	t.Setenv("KUBECONFIG", defaultPath)
+
+	configFlags.KubeConfig = explicitPath
+	t.Cleanup(func() { configFlags = kubeconfig.NewConfigFlags() })
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+
+	// Only the explicit file is loaded and written
+	require.Error(t, runContextSwitch(cmd, []string{"ctx1"}))
+	require.NoError(t, runContextSwitch(cmd, []string{"ctx3"}))
+
+	mgr, err := ctx.NewManager(configFlags)
+	require.NoError(t, err)
+	assert.Equal(t, "ctx3", mgr.GetCurrentContext())
+
+	mgr, err = ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+
+	// --context doesn't make a switch to the same context a no-op
+	configFlags.Overrides.CurrentContext = "ctx2"
+	require.NoError(t, runContextSwitch(cmd, []string{"ctx2"}))
+	config, err := clientcmd.LoadFromFile(explicitPath)
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", config.CurrentContext)
+
+	// --context must name an existing context
+	configFlags.Overrides.CurrentContext = "unknown"
+	assert.Error(t, runContextSwitch(cmd, []string{"ctx2"}))
+}
+
+func TestRunContextSwitch_Hooks(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": "", "ctx2": "web"})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	post := filepath.Join(dir, "post")
+	require.NoError(t, os.WriteFile(post, []byte("#!/bin/sh\necho \"$KUBECTL_CTX_OLD_CONTEXT $KUBECTL_CTX_NEW_CONTEXT $KUBECTL_CTX_NEW_NAMESPACE\" > "+out+"\n"), 0700))
+	deny := filepath.Join(dir, "deny")
+	require.NoError(t, os.WriteFile(deny, []byte("#!/bin/sh\nexit 1\n"), 0700))
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv(hooks.EnvPostHook, post)
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+
+	// A failing pre hook aborts the switch
+	t.Setenv(hooks.EnvPreHook, deny)
+	require.Error(t, runContextSwitch(cmd, []string{"ctx2"}))
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+	assert.NoFileExists(t, out)
+
+	t.Setenv(hooks.EnvPreHook, "")
+	require.NoError(t, runContextSwitch(cmd, []string{"ctx2"}))
+	content, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1 ctx2 web\n", string(content))
+}
+
+func TestRunContextSwitch_Login(t *testing.T) {
+	server, ca := testutil.NewFakeTLSAPIServer(t, nil)
+	plugin, calls := testutil.NewExecPlugin(t, testutil.NewJWT(time.Now().Add(time.Hour)))
+
+	config := api.NewConfig()
+	config.Clusters["eks"] = &api.Cluster{Server: server, CertificateAuthorityData: ca}
+	config.AuthInfos["eks"] = &api.AuthInfo{Exec: &api.ExecConfig{
+		APIVersion:      "client.authentication.k8s.io/v1",
+		Command:         plugin,
+		InteractiveMode: api.NeverExecInteractiveMode,
+	}}
+	config.Contexts["dev"] = &api.Context{Cluster: "eks", AuthInfo: "eks"}
+	config.Contexts["eks"] = &api.Context{Cluster: "eks", AuthInfo: "eks"}
+	config.CurrentContext = "dev"
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, clientcmd.WriteToFile(*config, kubeconfigPath))
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	login = true
+	t.Cleanup(func() { login = false })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runContextSwitch(cmd, []string{"eks"}))
+	assert.Contains(t, out.String(), `Switched to context "eks"`)
+	assert.Contains(t, out.String(), `Logged in to context "eks" via exec (server v1.36.3, expires `)
+	assert.FileExists(t, calls)
+}
+
+func TestStartClusterInfo(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	server := testutil.NewFakeAPIServer(t, nil)
+	kubeconfigPath := testutil.CreateKubeconfigWithServer(t, server, "ctx1", map[string]string{"ctx1": "", "ctx2": ""})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+
+	collector, err := startClusterInfo(mgr, mgr.ListContexts())
+	require.NoError(t, err)
+	collector.Wait()
+	assert.Equal(t, "v1.36.3, 3 nodes", collector.Describe("ctx1"))
+	assert.Equal(t, "v1.36.3, 3 nodes", collector.Describe("ctx2"))
+	require.NoError(t, collector.Stop())
+	assert.FileExists(t, collector.Path)
+}
+
+func TestRunContextSwitch_Plain(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	server := testutil.NewFakeAPIServer(t, []string{"default", "kube-system", "payments"})
+	kubeconfigPath := testutil.CreateKubeconfigWithServer(t, server, "ctx1", map[string]string{
+		"ctx1":    "",
+		"ctx2":    "",
+		"staging": "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	isInteractive := terminal.IsInteractive
+	terminal.IsInteractive = func() bool { return true }
+	plain, pickNamespace = true, true
+	t.Cleanup(func() {
+		terminal.IsInteractive = isInteractive
+		plain, pickNamespace = false, false
+	})
+
+	var out, prompt bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&prompt)
+	// An ambiguous prefix, then a unique one, then a namespace number
+	cmd.SetIn(strings.NewReader("ctx\nst\n3\n"))
+
+	require.NoError(t, runContextSwitch(cmd, nil))
+	assert.Equal(t, `1) ctx1 (current)
+2) ctx2
+3) staging
+Select context (number, name or prefix) [ctx1]: "ctx" matches several contexts: ctx1, ctx2
+Select context (number, name or prefix) [ctx1]: 1) default (current)
+2) kube-system
+3) payments
+Select namespace (number, name or prefix) [default]: `, prompt.String())
+
+	config, err := clientcmd.LoadFromFile(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", config.CurrentContext)
+	assert.Equal(t, "payments", config.Contexts["staging"].Namespace)
+}
+
+func TestRunContextSwitch_Sort(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+		"ctx3": "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	list := func() string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetOut(&out)
+		require.NoError(t, runContextSwitch(cmd, nil))
+		return out.String()
+	}
+
+	// Switches are recorded, making ctx3 the most frequent and ctx2 the most recent
+	for _, name := range []string{"ctx3", "ctx3", "ctx2"} {
+		require.NoError(t, runContextSwitch(&cobra.Command{}, []string{name}))
+	}
+	// Output read by scripts keeps the order by name
+	assert.Equal(t, "  ctx1\n* ctx2\n  ctx3\n", list())
+
+	t.Cleanup(func() { sortOrder = "" })
+	sortOrder = config.SortFrecency
+	assert.Equal(t, "  ctx3\n* ctx2\n  ctx1\n", list())
+	sortOrder = config.SortRecent
+	assert.Equal(t, "* ctx2\n  ctx3\n  ctx1\n", list())
+
+	// The config file sets the default
+	sortOrder = ""
+	testutil.WriteConfig(t, &config.Config{Sort: config.SortFrecency})
+	assert.Equal(t, "  ctx3\n* ctx2\n  ctx1\n", list())
+
+	path := testutil.WriteConfig(t, &config.Config{Sort: "random"})
+	err := runContextSwitch(&cobra.Command{}, nil)
+	assert.ErrorContains(t, err, `invalid config `+path+`:1:7: sort: invalid sort order "random", must be one of`)
+}
+
+func TestRunContextSwitch_Selector(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	kubeconfigPath := testutil.CreateKubeconfig(t, "dev", map[string]string{
+		"prod-eu": "",
+		"prod-us": "",
+		"dev":     "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	testutil.WriteConfig(t, &config.Config{
+		Sort: config.SortAlpha,
+		Tags: map[string]map[string]string{
+			"prod-*":  {"env": "prod"},
+			"prod-us": {"region": "us"},
+		},
+	})
+
+	t.Cleanup(func() { selector = "" })
+	list := func(s string) (string, error) {
+		t.Helper()
+		selector = s
+		var out bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetOut(&out)
+		err := runList(cmd, nil)
+		return out.String(), err
+	}
+
+	out, err := list("env=prod")
+	require.NoError(t, err)
+	assert.Equal(t, "  prod-eu\n  prod-us\n", out)
+
+	out, err = list("env=prod,region!=us")
+	require.NoError(t, err)
+	assert.Equal(t, "  prod-eu\n", out)
+
+	out, err = list("!env")
+	require.NoError(t, err)
+	assert.Equal(t, "* dev\n", out)
+
+	_, err = list("env in (prod")
+	assert.ErrorContains(t, err, "invalid selector")
+
+	// The selector has nothing to offer
+	isInteractive := terminal.IsInteractive
+	terminal.IsInteractive = func() bool { return true }
+	t.Cleanup(func() { terminal.IsInteractive = isInteractive })
+	selector = "env=staging"
+	err = runContextSwitch(&cobra.Command{}, nil)
+	assert.EqualError(t, err, `no contexts match selector "env=staging"`)
+}
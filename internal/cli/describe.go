@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// probe adds the live server version and reachability to describe
+var probe bool
+
+// newDescribeCommand returns the describe command
+func newDescribeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe [CONTEXT_NAME]",
+		Short: "Show everything the kubeconfig says about a context",
+		Long: `Show a context (the current one by default) with its default namespace, the
+server and TLS settings of its cluster (CA source, insecure-skip-tls-verify,
+TLS server name, proxy URL), how its user authenticates and any extensions,
+each with the kubeconfig file it comes from. Tokens, keys and other secrets
+are redacted.
+
+With --probe, the server is contacted to report whether it is reachable with
+the context's credentials and which Kubernetes version it runs.`,
+		Example: `  # Describe the current context
+  kubectl-ctx describe
+
+  # Describe a context and check its server
+  kubectl-ctx describe prod --probe`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runDescribe,
+	}
+
+	cmd.Flags().BoolVar(&probe, "probe", false, "contact the server to report its version and reachability")
+
+	return cmd
+}
+
+func runDescribe(cmd *cobra.Command, args []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	manager, err := context.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+
+	name := manager.GetCurrentContext()
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if name == "" {
+		return fmt.Errorf("no current context set, specify a context name")
+	}
+
+	d, err := manager.Describe(name)
+	if err != nil {
+		return err
+	}
+	if probe {
+		if d.Probe, err = manager.Probe(name); err != nil {
+			return err
+		}
+	}
+
+	return printer.Object(d, func(w io.Writer) error { return renderDescription(w, d) })
+}
+
+// renderDescription prints a description as indented fields
+func renderDescription(w io.Writer, d *context.Description) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	field := func(indent bool, key, value string) {
+		if value == "" {
+			return
+		}
+		prefix := ""
+		if indent {
+			prefix = "  "
+		}
+		_, _ = fmt.Fprintf(tw, "%s%s:\t%s\n", prefix, key, value)
+	}
+
+	c := d.Context
+	current := ""
+	if c.Current {
+		current = " (current)"
+	}
+	field(false, "Context", c.Name+current)
+	field(true, "File", c.File)
+	field(true, "Namespace", c.Namespace)
+	field(true, "Tags", labels.Set(c.Tags).String())
+	renderExtensions(field, c.Extensions)
+
+	if cl := d.Cluster; cl != nil {
+		ca := cl.CertificateAuthority
+		if ca == "" {
+			ca = "(system roots)"
+		}
+		field(false, "Cluster", cl.Name)
+		field(true, "File", cl.File)
+		field(true, "Server", cl.Server)
+		field(true, "Certificate authority", ca)
+		field(true, "Insecure skip TLS verify", strconv.FormatBool(cl.InsecureSkipTLSVerify))
+		field(true, "TLS server name", cl.TLSServerName)
+		field(true, "Proxy URL", cl.ProxyURL)
+		renderExtensions(field, cl.Extensions)
+	} else {
+		field(false, "Cluster", c.Cluster+" (not found)")
+	}
+
+	if u := d.User; u != nil {
+		field(false, "User", u.Name)
+		field(true, "File", u.File)
+		field(true, "Auth", strings.Join(u.Auth, ", "))
+		field(true, "Client certificate", u.ClientCertificate)
+		field(true, "Client key", u.ClientKey)
+		field(true, "Token", u.Token)
+		field(true, "Username", u.Username)
+		if e := u.Exec; e != nil {
+			field(true, "Exec command", strings.Join(append([]string{e.Command}, e.Args...), " "))
+			field(true, "Exec API version", e.APIVersion)
+			field(true, "Exec env", strings.Join(e.Env, ", "))
+			field(true, "Exec interactive mode", e.InteractiveMode)
+		}
+		if p := u.AuthProvider; p != nil {
+			field(true, "Auth provider", p.Name)
+			keys := make([]string, 0, len(p.Config))
+			for key := range p.Config {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				field(true, "  "+key, p.Config[key])
+			}
+		}
+		field(true, "Impersonate", u.Impersonate)
+		renderExtensions(field, u.Extensions)
+	} else if c.User != "" {
+		field(false, "User", c.User+" (not found)")
+	}
+
+	if p := d.Probe; p != nil {
+		if p.Reachable {
+			field(false, "Probe", "reachable ("+p.Latency+")")
+			field(true, "Server version", p.ServerVersion)
+		} else {
+			field(false, "Probe", "unreachable")
+			field(true, "Error", p.Error)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// renderExtensions prints extensions as JSON, sorted by name
+func renderExtensions(field func(indent bool, key, value string), extensions map[string]json.RawMessage) {
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field(true, "Extension "+name, string(extensions[name]))
+	}
+}
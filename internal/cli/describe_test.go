@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestRunDescribe(t *testing.T) {
+	config := api.NewConfig()
+	config.Clusters["test"] = &api.Cluster{Server: testutil.NewFakeAPIServer(t, nil)}
+	config.AuthInfos["admin"] = &api.AuthInfo{Token: "s3cr3t"}
+	config.Contexts["dev"] = &api.Context{Cluster: "test", AuthInfo: "admin", Namespace: "web"}
+	config.Contexts["prod"] = &api.Context{Cluster: "missing", AuthInfo: "admin"}
+	config.CurrentContext = "dev"
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, clientcmd.WriteToFile(*config, kubeconfigPath))
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runDescribe(cmd, nil))
+	assert.Regexp(t, `Context:\s+dev \(current\)\n\s+File:\s+`+kubeconfigPath+`\n\s+Namespace:\s+web\n`, out.String())
+	assert.Regexp(t, `Certificate authority:\s+\(system roots\)`, out.String())
+	assert.Regexp(t, `Auth:\s+token\n\s+Token:\s+REDACTED\n`, out.String())
+	assert.NotContains(t, out.String(), "s3cr3t")
+	assert.NotContains(t, out.String(), "Probe:")
+
+	probe = true
+	t.Cleanup(func() { probe = false })
+
+	out.Reset()
+	require.NoError(t, runDescribe(cmd, nil))
+	assert.Regexp(t, `Probe:\s+reachable \(\S+\)\n\s+Server version:\s+v1.36.3\n`, out.String())
+
+	outputFormat = output.FormatJSON
+	t.Cleanup(func() { outputFormat = output.FormatText })
+
+	out.Reset()
+	require.NoError(t, runDescribe(cmd, []string{"prod"}))
+	var d context.Description
+	require.NoError(t, json.Unmarshal(out.Bytes(), &d))
+	assert.Equal(t, "prod", d.Context.Name)
+	assert.Nil(t, d.Cluster)
+	require.NotNil(t, d.Probe)
+	assert.False(t, d.Probe.Reachable)
+	assert.NotEmpty(t, d.Probe.Error)
+
+	assert.Error(t, runDescribe(cmd, []string{"unknown"}))
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// newExplainCommand returns the explain command
+func newExplainCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain [[FILE:]CONTEXT_NAME]",
+		Short: "Explain how KUBECONFIG files are merged and where switches write",
+		Long: `Show each kubeconfig file in loading precedence, which file every context,
+cluster and user comes from, which duplicates are shadowed (the first file
+defining a name wins), and which files a switch writes current-context and the
+namespace to.
+
+With a context name, only that context and its cluster and user are shown.
+FILE:CONTEXT_NAME shows the context as defined in the given file, including
+a shadowed one.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runExplain,
+	}
+}
+
+// explanation is the result of the explain command
+type explanation struct {
+	Files              []kubeconfig.File       `json:"files"`
+	CurrentContext     string                  `json:"currentContext"`
+	CurrentContextFile string                  `json:"currentContextFile,omitempty"`
+	Definitions        []kubeconfig.Definition `json:"definitions"`
+	Writes             writeTargets            `json:"writes"`
+}
+
+// writeTargets are the files a switch writes to
+type writeTargets struct {
+	CurrentContext string `json:"currentContext"`
+	Context        string `json:"context,omitempty"`
+	Namespace      string `json:"namespace,omitempty"`
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	manager, err := context.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+
+	sources, err := manager.Sources()
+	if err != nil {
+		return err
+	}
+
+	e := explanation{
+		Files:              sources.Files,
+		CurrentContext:     manager.GetCurrentContext(),
+		CurrentContextFile: sources.CurrentContextFile,
+		Definitions:        sources.Definitions,
+		Writes: writeTargets{
+			CurrentContext: manager.CurrentContextFile(),
+			Context:        manager.GetCurrentContext(),
+		},
+	}
+
+	if len(args) > 0 {
+		name := args[0]
+		ctx, err := manager.GetContext(name)
+		if err != nil {
+			ref, ok, refErr := manager.ParseFileRef(name)
+			if refErr != nil {
+				return refErr
+			}
+			if !ok {
+				return err
+			}
+			if ctx = sources.Config(ref.File).Contexts[ref.Name]; ctx == nil {
+				return fmt.Errorf("context %q not found in %s", ref.Name, ref.File)
+			}
+			name = ref.Name
+		}
+
+		e.Definitions = nil
+		e.Definitions = append(e.Definitions, sources.Lookup(kubeconfig.KindContext, name)...)
+		e.Definitions = append(e.Definitions, sources.Lookup(kubeconfig.KindCluster, ctx.Cluster)...)
+		e.Definitions = append(e.Definitions, sources.Lookup(kubeconfig.KindUser, ctx.AuthInfo)...)
+		e.Writes.Context = name
+	}
+
+	if e.Writes.Context != "" {
+		e.Writes.Namespace = manager.ContextFile(e.Writes.Context)
+	}
+
+	return printer.Object(e, e.render)
+}
+
+func (e explanation) render(w io.Writer) error {
+	_, _ = fmt.Fprintln(w, "Loading precedence (first file defining a name wins):")
+	for i, f := range e.Files {
+		status := ""
+		if !f.Exists {
+			status = " (missing)"
+		}
+		_, _ = fmt.Fprintf(w, "  %d. %s%s\n", i+1, f.Path, status)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	if e.CurrentContext == "" {
+		_, _ = fmt.Fprintln(w, "Current context: (none)")
+	} else {
+		_, _ = fmt.Fprintf(w, "Current context: %s (set in %s)\n", e.CurrentContext, e.CurrentContextFile)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "KIND\tNAME\tFILE\tSTATUS")
+	for _, d := range e.Definitions {
+		status := "active"
+		if d.ShadowedBy != "" {
+			status = "shadowed by " + d.ShadowedBy
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.Kind, d.Name, d.File, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Switching writes:")
+	_, _ = fmt.Fprintf(w, "  current-context -> %s\n", e.Writes.CurrentContext)
+	if e.Writes.Context != "" {
+		_, err := fmt.Fprintf(w, "  namespace of %s -> %s\n", e.Writes.Context, e.Writes.Namespace)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	kubeconfig1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1":   "",
+		"shared": "",
+	})
+	kubeconfig2 := testutil.CreateKubeconfig(t, "ctx2", map[string]string{
+		"ctx2":   "",
+		"shared": "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfig1+":"+kubeconfig2)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runExplain(cmd, nil))
+	assert.Contains(t, out.String(), "  1. "+kubeconfig1+"\n  2. "+kubeconfig2+"\n")
+	assert.Contains(t, out.String(), "Current context: ctx1 (set in "+kubeconfig1+")")
+	assert.Regexp(t, `context\s+shared\s+`+kubeconfig2+`\s+shadowed by `+kubeconfig1, out.String())
+	assert.Contains(t, out.String(), "current-context -> "+kubeconfig1)
+	assert.Contains(t, out.String(), "namespace of ctx1 -> "+kubeconfig1)
+}
+
+func TestExplain_Context(t *testing.T) {
+	kubeconfig1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": ""})
+	kubeconfig2 := testutil.CreateKubeconfig(t, "ctx2", map[string]string{"ctx2": ""})
+	t.Setenv("KUBECONFIG", kubeconfig1+":"+kubeconfig2)
+
+	outputFormat = output.FormatJSON
+	t.Cleanup(func() { outputFormat = output.FormatText })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runExplain(cmd, []string{"ctx2"}))
+
+	var e explanation
+	require.NoError(t, json.Unmarshal(out.Bytes(), &e))
+	assert.Equal(t, kubeconfig1, e.Writes.CurrentContext)
+	assert.Equal(t, kubeconfig2, e.Writes.Namespace)
+	require.NotEmpty(t, e.Definitions)
+	assert.Equal(t, "ctx2", e.Definitions[0].Name)
+	assert.Equal(t, kubeconfig2, e.Definitions[0].File)
+
+	assert.Error(t, runExplain(cmd, []string{"unknown"}))
+}
+
+func TestExplain_ShadowedContext(t *testing.T) {
+	kubeconfig1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"shared": ""})
+	kubeconfig2 := testutil.CreateKubeconfig(t, "ctx2", map[string]string{"shared": ""})
+	t.Setenv("KUBECONFIG", kubeconfig1+":"+kubeconfig2)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runExplain(cmd, []string{kubeconfig2 + ":shared"}))
+	assert.Regexp(t, `context\s+shared\s+`+kubeconfig2+`\s+shadowed by `+kubeconfig1, out.String())
+	assert.Contains(t, out.String(), "namespace of shared -> "+kubeconfig1)
+}
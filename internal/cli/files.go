@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// newFilesCommand returns the files command with its subcommands
+func newFilesCommand() *cobra.Command {
+	filesCmd := &cobra.Command{
+		Use:   "files",
+		Short: "List the kubeconfig files in loading precedence",
+		Long: `List the kubeconfig files both tools load, in loading precedence, with the
+reason each one is loaded: --kubeconfig, KUBECONFIG, the default
+~/.kube/config, or discovery.
+
+Files matching the glob patterns in KUBECTL_CTX_KUBECONFIG_DIRS (separated
+like PATH) or kubeconfigDirs in the tool config are discovered and loaded
+after the others. kubectl itself doesn't load discovered files; add them to
+KUBECONFIG to use their contexts with kubectl.
+
+"files enable" and "files disable" add files to or remove them from the
+loading precedence of both tools, persisted in the tool config. Like "files
+export", they print a KUBECONFIG export of the resulting files, so kubectl
+can follow with eval.`,
+		Example: `  # Stop loading a file and update KUBECONFIG for kubectl
+  eval "$(kubectl-ctx files disable ~/.kube/configs/old.yaml)"
+
+  # Load an additional file
+  eval "$(kubectl-ctx files enable ~/Downloads/kubeconfig.yaml)"`,
+		Args: cobra.NoArgs,
+		RunE: runFiles,
+	}
+
+	filesCmd.AddCommand(&cobra.Command{
+		Use:   "enable FILE...",
+		Short: "Load kubeconfig files, after all others",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runFilesEnable,
+	}, &cobra.Command{
+		Use:   "disable FILE...",
+		Short: "Stop loading kubeconfig files, even when in KUBECONFIG or discovered",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runFilesDisable,
+	}, &cobra.Command{
+		Use:   "export",
+		Short: "Print a KUBECONFIG export of the loaded files for eval",
+		Args:  cobra.NoArgs,
+		RunE:  runFilesExport,
+	})
+
+	return filesCmd
+}
+
+// fileEntry is a kubeconfig file listed by the files command
+type fileEntry struct {
+	kubeconfig.FileSource
+	Exists   bool     `json:"exists"`
+	Contexts []string `json:"contexts"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func runFiles(cmd *cobra.Command, _ []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	files, err := configFlags.Files()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]fileEntry, 0, len(files))
+	rows := make([][]string, 0, len(files))
+	for _, f := range files {
+		e := fileEntry{FileSource: f, Contexts: []string{}}
+		config, err := clientcmd.LoadFromFile(f.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			e.Exists = true
+			e.Error = err.Error()
+		default:
+			e.Exists = true
+			for name := range config.Contexts {
+				e.Contexts = append(e.Contexts, name)
+			}
+			slices.Sort(e.Contexts)
+		}
+		entries = append(entries, e)
+
+		source := f.Source
+		if f.Pattern != "" {
+			source += " (" + f.Pattern + ")"
+		}
+		status := strconv.Itoa(len(e.Contexts)) + " contexts"
+		switch {
+		case f.Disabled:
+			status = "disabled"
+		case !e.Exists:
+			status = "missing"
+		case e.Error != "":
+			status = "error: " + e.Error
+		}
+		rows = append(rows, []string{f.Path, source, status})
+	}
+
+	return printer.Table([]string{"file", "source", "status"}, rows, entries)
+}
+
+func runFilesEnable(cmd *cobra.Command, args []string) error {
+	if err := checkFileSet(); err != nil {
+		return err
+	}
+
+	files, err := configFlags.Files()
+	if err != nil {
+		return err
+	}
+
+	err = config.Update(func(c *config.Config) error {
+		for _, arg := range args {
+			path := kubeconfig.AbsPath(arg)
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("cannot enable kubeconfig file: %w", err)
+			}
+
+			// Files loaded anyway only need to be removed from the disabled ones
+			add := !slices.ContainsFunc(files, func(f kubeconfig.FileSource) bool {
+				return f.Path == path && f.Source != kubeconfig.SourceEnabled
+			})
+			c.KubeconfigFiles.Enable(path, add)
+			slog.Info("Enabled kubeconfig file", "file", path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := reloadToolConfig(); err != nil {
+		return err
+	}
+
+	return runFilesExport(cmd, nil)
+}
+
+func runFilesDisable(cmd *cobra.Command, args []string) error {
+	if err := checkFileSet(); err != nil {
+		return err
+	}
+
+	err := config.Update(func(c *config.Config) error {
+		for _, arg := range args {
+			path := kubeconfig.AbsPath(arg)
+			c.KubeconfigFiles.Disable(path)
+			slog.Info("Disabled kubeconfig file", "file", path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := reloadToolConfig(); err != nil {
+		return err
+	}
+
+	return runFilesExport(cmd, nil)
+}
+
+// runFilesExport prints the loaded files as a KUBECONFIG export
+func runFilesExport(cmd *cobra.Command, _ []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	files, err := configFlags.Files()
+	if err != nil {
+		return err
+	}
+
+	value := strings.Join(kubeconfig.ActivePaths(files), string(os.PathListSeparator))
+	return printer.Object(map[string]string{clientcmd.RecommendedConfigPathEnvVar: value}, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "export %s=%s\n", clientcmd.RecommendedConfigPathEnvVar, shellQuote(value))
+		return err
+	})
+}
+
+// checkFileSet rejects changing the file set with --kubeconfig, which
+// replaces it
+func checkFileSet() error {
+	if configFlags.KubeConfig != "" {
+		return fmt.Errorf("--kubeconfig selects a single file, the file set can't be changed with it")
+	}
+	return nil
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"testing"
+
+	ctx "github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// setupDiscovery points KUBECONFIG at a file with ctx1 and discovers a
+// second file with kind-dev, returning both paths
+func setupDiscovery(t *testing.T) (string, string) {
+	t.Helper()
+
+	main := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": ""})
+	discovered := testutil.CreateKubeconfig(t, "", map[string]string{"kind-dev": ""})
+	t.Setenv("KUBECONFIG", main)
+	t.Setenv(kubeconfig.EnvDirs, filepath.Join(filepath.Dir(discovered), "*"))
+	return main, discovered
+}
+
+func TestRunFiles(t *testing.T) {
+	main, discovered := setupDiscovery(t)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runFiles(cmd, nil))
+	assert.Regexp(t, main+`\s+KUBECONFIG\s+1 contexts\n`, out.String())
+	assert.Regexp(t, discovered+`\s+discovered \(\S+\)\s+1 contexts\n`, out.String())
+
+	outputFormat = output.FormatJSON
+	t.Cleanup(func() { outputFormat = output.FormatText })
+
+	out.Reset()
+	require.NoError(t, runFiles(cmd, nil))
+	var entries []fileEntry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, kubeconfig.SourceDiscovered, entries[1].Source)
+	assert.Equal(t, []string{"kind-dev"}, entries[1].Contexts)
+}
+
+func TestRunContextSwitch_DiscoveredContext(t *testing.T) {
+	main, discovered := setupDiscovery(t)
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+
+	// kubectl doesn't load the discovered file, so it couldn't resolve the
+	// context
+	err := runContextSwitch(cmd, []string{"kind-dev/web"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "files export")
+	config, err := clientcmd.LoadFromFile(main)
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", config.CurrentContext)
+
+	// Once exported, kubectl's own loading rules follow the switch
+	t.Setenv("KUBECONFIG", main+string(filepath.ListSeparator)+discovered)
+	require.NoError(t, runContextSwitch(cmd, []string{"kind-dev/web"}))
+
+	kubectl := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+	raw, err := kubectl.RawConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "kind-dev", raw.CurrentContext)
+	assert.Contains(t, raw.Contexts, "kind-dev")
+	namespace, _, err := kubectl.Namespace()
+	require.NoError(t, err)
+	assert.Equal(t, "web", namespace)
+
+	// current-context goes to the first file, the namespace to the file
+	// defining the context
+	config, err = clientcmd.LoadFromFile(main)
+	require.NoError(t, err)
+	assert.Equal(t, "kind-dev", config.CurrentContext)
+	config, err = clientcmd.LoadFromFile(discovered)
+	require.NoError(t, err)
+	assert.Equal(t, "web", config.Contexts["kind-dev"].Namespace)
+}
+
+func TestRunContextSwitch_DisabledFirstFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	main, _ := setupDiscovery(t)
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+	require.NoError(t, runFilesDisable(cmd, []string{main}))
+
+	// kubectl would still read current-context from the disabled file
+	err := runContextSwitch(cmd, []string{"kind-dev"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), main+" is disabled but kubectl still loads it")
+}
+
+func TestRunFilesEnableDisable(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	main, discovered := setupDiscovery(t)
+	extra := testutil.CreateKubeconfig(t, "", map[string]string{"extra": ""})
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runFilesEnable(cmd, []string{extra}))
+	assert.Equal(t, "export KUBECONFIG='"+main+":"+discovered+":"+extra+"'\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runFilesDisable(cmd, []string{main}))
+	assert.Equal(t, "export KUBECONFIG='"+discovered+":"+extra+"'\n", out.String())
+
+	// Both managers honor the file set
+	mgr, err := ctx.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"extra", "kind-dev"}, mgr.ListContexts())
+
+	out.Reset()
+	require.NoError(t, runFiles(cmd, nil))
+	assert.Regexp(t, main+`\s+KUBECONFIG\s+disabled\n`, out.String())
+	assert.Regexp(t, extra+`\s+enabled\s+1 contexts\n`, out.String())
+
+	// Re-enabling a file from KUBECONFIG doesn't add it again
+	out.Reset()
+	require.NoError(t, runFilesEnable(cmd, []string{main}))
+	assert.Equal(t, "export KUBECONFIG='"+main+":"+discovered+":"+extra+"'\n", out.String())
+
+	assert.Error(t, runFilesEnable(cmd, []string{filepath.Join(t.TempDir(), "missing")}))
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'/tmp/it'\''s'`, shellQuote("/tmp/it's"))
+}
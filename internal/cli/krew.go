@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/camaeel/kubectl-ctx/internal/krew"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// krewVersion is the release tag the manifest points to
+	krewVersion string
+	// krewChecksums is the sha256sum file of the release archives
+	krewChecksums string
+)
+
+// newKrewManifestCommand returns the command generating krew manifests
+func newKrewManifestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "krew-manifest PLUGIN",
+		Short: "Generate the krew plugin manifest (ctx or ns) for a release",
+		Long: `Print the krew plugin manifest for the ctx or ns plugin of a release.
+Both plugins install the same kubectl-ctx binary from the release archives,
+under the name of the command they provide.`,
+		Example: `  kubectl-ctx krew-manifest ctx --version v1.2.3 --checksums SHA256SUMS > ctx.yaml`,
+		Args:    cobra.ExactArgs(1),
+		Hidden:  true,
+		RunE:    runKrewManifest,
+	}
+
+	cmd.Flags().StringVar(&krewVersion, "version", Version, "release tag of the archives")
+	cmd.Flags().StringVar(&krewChecksums, "checksums", "", "sha256sum file of the release archives")
+	_ = cmd.MarkFlagRequired("checksums")
+
+	return cmd
+}
+
+func runKrewManifest(cmd *cobra.Command, args []string) error {
+	plugin, ok := krew.Plugins[args[0]]
+	if !ok {
+		names := make([]string, 0, len(krew.Plugins))
+		for name := range krew.Plugins {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return fmt.Errorf("unknown plugin %q, expected one of %s", args[0], strings.Join(names, ", "))
+	}
+
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(krewChecksums)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	checksums, err := krew.ParseChecksums(f)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := krew.NewManifest(plugin, krewVersion, checksums)
+	if err != nil {
+		return err
+	}
+
+	return printer.Object(manifest, func(w io.Writer) error {
+		content, err := yaml.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	})
+}
@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/hooks"
+	ns "github.com/camaeel/kubectl-ctx/internal/namespace"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/picker"
+	"github.com/camaeel/kubectl-ctx/internal/pins"
+	"github.com/camaeel/kubectl-ctx/internal/utils/logging"
+	"github.com/camaeel/kubectl-ctx/internal/utils/terminal"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+var (
+	// showCurrentNamespace prints the current namespace and exits
+	showCurrentNamespace bool
+	// pinNamespace and unpinNamespace pin or unpin a namespace of the context
+	pinNamespace, unpinNamespace bool
+	// showPinnedNamespaces prints the pinned namespaces of the context
+	showPinnedNamespaces bool
+)
+
+// newNamespaceCommand returns the namespace switcher named use
+func newNamespaceCommand(use string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use + " [NAMESPACE]",
+		Short: "Switch between Kubernetes namespaces",
+		Long: `kubectl-ns is a tool for switching namespaces in the current Kubernetes context.
+
+With no arguments, it shows the current namespace and provides an interactive
+menu to select a new namespace (fetched from the cluster if accessible).
+With --plain (the default when TERM=dumb), the menu is a numbered list
+answered with a number, name or prefix. With a namespace argument, it
+switches directly to that namespace. When stdin or stdout is not a terminal,
+it prints the list of namespaces instead, marking the current one with "*".
+
+Results (lists, the current namespace, switch results) are printed to stdout,
+while diagnostics are logged to stderr.
+
+The tool automatically handles multiple KUBECONFIG files (e.g., KUBECONFIG=file1:file2).
+Like kubectl, --kubeconfig selects a single file instead, --context selects
+the context whose namespace is shown and switched, and --cluster and --user
+override the connection used to list namespaces.
+
+--pin and --unpin pin or unpin a namespace (the current one by default) of the
+context. Pinned namespaces are listed at the top of the interactive menu and
+first in shell completions, and --pinned lists them for scripts.`,
+		Example: `  # Show current namespace and select interactively
+  kubectl-ns
+
+  # Switch to a specific namespace
+  kubectl-ns kube-system
+
+  # Switch the namespace of another context
+  kubectl-ns --context staging kube-system
+
+  # Print the current namespace for use in scripts
+  NS=$(kubectl-ns --current)
+
+  # Switch and report the result as JSON
+  kubectl-ns kube-system -o json
+
+  # Pin a namespace of the current context
+  kubectl-ns --pin payments`,
+		Args:              cobra.MaximumNArgs(1),
+		RunE:              runNamespaceSwitch,
+		ValidArgsFunction: completeNamespaces,
+	}
+
+	cmd.Flags().BoolVarP(&showCurrentNamespace, "current", "c", false, "print the current namespace")
+	cmd.Flags().BoolVar(&pinNamespace, "pin", false, "pin the namespace (the current one by default) of the context")
+	cmd.Flags().BoolVar(&unpinNamespace, "unpin", false, "unpin the namespace (the current one by default) of the context")
+	cmd.Flags().BoolVar(&showPinnedNamespaces, "pinned", false, "print the pinned namespaces of the context")
+	cmd.MarkFlagsMutuallyExclusive("current", "pin", "unpin", "pinned")
+
+	return cmd
+}
+
+func runNamespaceSwitch(cmd *cobra.Command, args []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	// Create namespace manager
+	manager, err := ns.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+
+	// Get current namespace
+	currentNamespace := manager.GetCurrentNamespace()
+
+	if showCurrentNamespace {
+		return printer.Current(currentNamespace)
+	}
+
+	p, err := pins.Load()
+	if err != nil {
+		return err
+	}
+	pinned := p.NamespacesOf(manager.GetCurrentContext())
+
+	if showPinnedNamespaces {
+		return printer.List(pinned, currentNamespace)
+	}
+	if pinNamespace || unpinNamespace {
+		name := currentNamespace
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return updateNamespacePin(manager.GetCurrentContext(), name, pinNamespace)
+	}
+
+	var targetNamespace string
+
+	// If argument provided, use it; otherwise show interactive selection
+	if len(args) > 0 {
+		targetNamespace = args[0]
+	} else {
+		// Try to get namespaces from cluster
+		namespaces, err := manager.GetNamespacesFromCluster()
+		if err != nil {
+			return fmt.Errorf("failed to fetch namespaces from cluster: %w", err)
+		}
+
+		if !terminal.IsInteractive() {
+			// Not a terminal: print the list for scripts instead of prompting
+			names := ns.Names(namespaces)
+			slices.Sort(names)
+			return printer.List(names, currentNamespace)
+		}
+
+		// Show current namespace
+		slog.Info("Current namespace", "namespace", currentNamespace, "context", manager.GetCurrentContext())
+
+		// Show interactive selection with actual namespaces
+		targetNamespace, err = pickNamespaceFrom(cmd, namespaces, currentNamespace, pinned)
+		if err != nil {
+			return err
+		}
+	}
+
+	result := output.SwitchResult{
+		Kind: "namespace",
+		From: currentNamespace,
+		To:   targetNamespace,
+		File: manager.ContextFile(),
+	}
+
+	// Don't switch if already on target namespace
+	if targetNamespace == currentNamespace {
+		return printer.Switch(result)
+	}
+
+	event := hooks.Event{
+		Kind:         "namespace",
+		OldContext:   manager.GetCurrentContext(),
+		NewContext:   manager.GetCurrentContext(),
+		OldNamespace: currentNamespace,
+		NewNamespace: targetNamespace,
+		OldServer:    manager.GetServer(),
+		NewServer:    manager.GetServer(),
+	}
+
+	// Switch namespace
+	if err := withHooks(event, func() error { return manager.SwitchNamespace(targetNamespace) }); err != nil {
+		return err
+	}
+
+	result.Changed = true
+	result.DryRun = dryRun
+	return printer.Switch(result)
+}
+
+// updateNamespacePin pins or unpins a namespace of a context
+func updateNamespacePin(contextName, name string, pin bool) error {
+	if err := ns.ValidateNamespace(name); err != nil {
+		return err
+	}
+
+	return pins.Update(func(p *pins.Pins) error {
+		switch {
+		case pin && p.PinNamespace(contextName, name):
+			slog.Info("Pinned namespace", "namespace", name, "context", contextName)
+		case pin:
+			slog.Info("Namespace is already pinned", "namespace", name, "context", contextName)
+		case p.UnpinNamespace(contextName, name):
+			slog.Info("Unpinned namespace", "namespace", name, "context", contextName)
+		default:
+			slog.Warn("Namespace is not pinned", "namespace", name, "context", contextName)
+		}
+		return nil
+	})
+}
+
+// completeNamespaces completes the namespaces of the context, pinned ones
+// first. When the cluster can't be reached, only pinned namespaces are
+// completed.
+func completeNamespaces(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	manager, err := ns.NewManager(configFlags)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	p, err := pins.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	pinned := p.NamespacesOf(manager.GetCurrentContext())
+	directive := cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+
+	names, err := manager.ListNamespacesFromCluster()
+	if err != nil {
+		slog.Debug("Failed to fetch namespaces for completion", "error", err)
+		return pinned, directive
+	}
+	slices.Sort(names)
+	names, _ = pins.First(names, pinned)
+	return names, directive
+}
+
+// pickNamespaceFrom interactively selects one of namespaces, previewing the
+// highlighted one. The current namespace is highlighted first, or the
+// default namespace if the current one doesn't exist. Pinned namespaces are
+// listed first.
+func pickNamespaceFrom(cmd *cobra.Command, namespaces []corev1.Namespace, currentNamespace string, pinned []string) (string, error) {
+	names := ns.Names(namespaces)
+	if !slices.Contains(names, currentNamespace) {
+		currentNamespace = ns.DefaultNamespace
+	}
+
+	return pick(cmd, picker.Options{
+		Kind:    "namespace",
+		Items:   names,
+		Current: currentNamespace,
+		Pinned:  pinned,
+		Preview: func(name string) string {
+			i := slices.Index(names, name)
+			if i < 0 {
+				return ""
+			}
+			var b strings.Builder
+			if err := renderNamespace(&b, &namespaces[i], time.Now()); err != nil {
+				return err.Error()
+			}
+			return b.String()
+		},
+		Color: logging.ColorEnabled(os.Stderr),
+	})
+}
+
+// renderNamespace prints the status, age, labels and annotations of a
+// namespace for the preview pane
+func renderNamespace(w io.Writer, namespace *corev1.Namespace, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Namespace:\t%s\n", namespace.Name)
+	_, _ = fmt.Fprintf(tw, "  Status:\t%s\n", namespace.Status.Phase)
+	if created := namespace.CreationTimestamp; !created.IsZero() {
+		_, _ = fmt.Fprintf(tw, "  Created:\t%s (%s ago)\n", created.UTC().Format(time.RFC3339),
+			duration.HumanDuration(now.Sub(created.Time)))
+	}
+	renderMap(tw, "Labels", namespace.Labels)
+	renderMap(tw, "Annotations", namespace.Annotations)
+	return tw.Flush()
+}
+
+// renderMap prints labels or annotations sorted by key, one per line
+func renderMap(w io.Writer, title string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "  %s:\n", title)
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		// Last applied configurations are whole manifests, too long to preview
+		if key == corev1.LastAppliedConfigAnnotation {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "    %s=%s\n", key, values[key])
+	}
+}
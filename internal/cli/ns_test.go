@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	ns "github.com/camaeel/kubectl-ctx/internal/namespace"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestRunNamespaceSwitch_WithArgument(t *testing.T) {
+	// Create test kubeconfig
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "initial-ns",
+	})
+
+	// Set KUBECONFIG environment variable (automatically restored after test)
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	// Create a new command with args
+	cmd := &cobra.Command{}
+	args := []string{"target-ns"}
+
+	// Run the switch
+	err := runNamespaceSwitch(cmd, args)
+	require.NoError(t, err)
+
+	// Verify the namespace was changed
+	mgr, err := ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "target-ns", mgr.GetCurrentNamespace())
+}
+
+func TestRunNamespaceSwitch_AlreadyOnTargetNamespace(t *testing.T) {
+	// Create test kubeconfig with namespace already set
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "my-namespace",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	// Try to switch to the same namespace
+	cmd := &cobra.Command{}
+	args := []string{"my-namespace"}
+
+	err := runNamespaceSwitch(cmd, args)
+	require.NoError(t, err)
+
+	// Verify the namespace is still the same
+	mgr, err := ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "my-namespace", mgr.GetCurrentNamespace())
+}
+
+func TestRunNamespaceSwitch_SwitchFromDefault(t *testing.T) {
+	// Create test kubeconfig without namespace (defaults to "default")
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	cmd := &cobra.Command{}
+	args := []string{"new-namespace"}
+
+	err := runNamespaceSwitch(cmd, args)
+	require.NoError(t, err)
+
+	// Verify the namespace was changed
+	mgr, err := ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "new-namespace", mgr.GetCurrentNamespace())
+}
+
+func TestRunNamespaceSwitch_InvalidKubeconfig(t *testing.T) {
+	// Set KUBECONFIG to non-existent file
+	tmpDir := t.TempDir()
+	t.Setenv("KUBECONFIG", filepath.Join(tmpDir, "nonexistent"))
+
+	cmd := &cobra.Command{}
+	args := []string{"some-namespace"}
+
+	err := runNamespaceSwitch(cmd, args)
+	assert.Error(t, err)
+}
+
+func TestRunNamespaceSwitch_NoCurrentContext(t *testing.T) {
+	// Create kubeconfig without current-context
+	kubeconfigPath := testutil.CreateKubeconfig(t, "", map[string]string{
+		"test-ctx": "",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	cmd := &cobra.Command{}
+	args := []string{"some-namespace"}
+
+	err := runNamespaceSwitch(cmd, args)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no current context")
+}
+
+func TestRunNamespaceSwitch_MultipleNamespaceSwitches(t *testing.T) {
+	// Create test kubeconfig
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "ns1",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	cmd := &cobra.Command{}
+
+	// Switch to ns2
+	err := runNamespaceSwitch(cmd, []string{"ns2"})
+	require.NoError(t, err)
+
+	mgr, err := ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ns2", mgr.GetCurrentNamespace())
+
+	// Switch to ns3
+	err = runNamespaceSwitch(cmd, []string{"ns3"})
+	require.NoError(t, err)
+
+	mgr, err = ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ns3", mgr.GetCurrentNamespace())
+
+	// Switch back to ns1
+	err = runNamespaceSwitch(cmd, []string{"ns1"})
+	require.NoError(t, err)
+
+	mgr, err = ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ns1", mgr.GetCurrentNamespace())
+}
+
+func TestRunNamespaceSwitch_MultipleKubeconfigFiles(t *testing.T) {
+	// Create two kubeconfig files
+	kubeconfig1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "ns1",
+	})
+
+	kubeconfig2 := testutil.CreateKubeconfig(t, "ctx2", map[string]string{
+		"ctx2": "ns2",
+	})
+
+	// Set KUBECONFIG with multiple files (colon-separated on Unix)
+	t.Setenv("KUBECONFIG", kubeconfig1+":"+kubeconfig2)
+
+	// Verify current context from first file
+	mgr, err := ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+	assert.Equal(t, "ns1", mgr.GetCurrentNamespace())
+
+	// Switch namespace in the current context
+	cmd := &cobra.Command{}
+	err = runNamespaceSwitch(cmd, []string{"new-ns"})
+	require.NoError(t, err)
+
+	// Verify the switch
+	mgr, err = ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "new-ns", mgr.GetCurrentNamespace())
+	assert.Equal(t, "ctx1", mgr.GetCurrentContext())
+}
+
+func TestRunNamespaceSwitch_InvalidNamespace(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "initial-ns",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	cmd := &cobra.Command{}
+	err := runNamespaceSwitch(cmd, []string{"My_Namespace"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid namespace")
+
+	mgr, err := ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "initial-ns", mgr.GetCurrentNamespace())
+}
+
+func TestRunNamespaceSwitch_NonInteractiveListsNamespaces(t *testing.T) {
+	server := testutil.NewFakeAPIServer(t, []string{"kube-system", "default", "payments"})
+	kubeconfigPath := testutil.CreateKubeconfigWithServer(t, server, "test-ctx", map[string]string{
+		"test-ctx": "payments",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	err := runNamespaceSwitch(cmd, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "  default\n  kube-system\n* payments\n", out.String())
+}
+
+func TestRunNamespaceSwitch_JSONOutput(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "initial-ns",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	outputFormat = output.FormatJSON
+	t.Cleanup(func() { outputFormat = output.FormatText })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	err := runNamespaceSwitch(cmd, []string{"target-ns"})
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "initial-ns", result["from"])
+	assert.Equal(t, "target-ns", result["to"])
+	assert.Equal(t, kubeconfigPath, result["file"])
+}
+
+func TestRunNamespaceSwitch_ShowCurrent(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "my-ns",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	showCurrentNamespace = true
+	t.Cleanup(func() { showCurrentNamespace = false })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	err := runNamespaceSwitch(cmd, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "my-ns\n", out.String())
+}
+
+func TestRunNamespaceSwitch_DryRun(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "initial-ns",
+	})
+
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	printer, err := output.New(&out, output.FormatText)
+	require.NoError(t, err)
+	configFlags.Write.DryRun = kubeconfig.ReportDiffs(printer.Diff)
+	dryRun = true
+	t.Cleanup(func() {
+		configFlags.Write = kubeconfig.UpdateOptions{}
+		dryRun = false
+	})
+
+	err = runNamespaceSwitch(cmd, []string{"target-ns"})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "-    namespace: initial-ns\n")
+	assert.Contains(t, out.String(), "+    namespace: target-ns\n")
+	assert.Contains(t, out.String(), `Would switch to namespace "target-ns"`)
+
+	mgr, err := ns.NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "initial-ns", mgr.GetCurrentNamespace())
+}
+
+func TestRunNamespaceSwitch_ConfigFlags(t *testing.T) {
+	kubeconfigPath := testutil.CreateKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx":  "initial-ns",
+		"other-ctx": "",
+	})
+	t.Setenv("KUBECONFIG", "")
+
+	configFlags.KubeConfig = kubeconfigPath
+	configFlags.Overrides.CurrentContext = "other-ctx"
+	t.Cleanup(func() { configFlags = kubeconfig.NewConfigFlags() })
+
+	cmd := &cobra.Command{}
+	require.NoError(t, runNamespaceSwitch(cmd, []string{"target-ns"}))
+
+	config, err := clientcmd.LoadFromFile(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, "test-ctx", config.CurrentContext)
+	assert.Equal(t, "initial-ns", config.Contexts["test-ctx"].Namespace)
+	assert.Equal(t, "target-ns", config.Contexts["other-ctx"].Namespace)
+}
+
+func TestRenderNamespace(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "payments",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{"team": "billing", "env": "prod"},
+			Annotations: map[string]string{
+				"owner":                            "billing@example.com",
+				corev1.LastAppliedConfigAnnotation: `{"apiVersion":"v1"}`,
+			},
+		},
+		Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, renderNamespace(&out, namespace, created.Add(49*time.Hour)))
+	assert.Equal(t, `Namespace:  payments
+  Status:   Active
+  Created:  2026-01-02T03:04:05Z (2d1h ago)
+  Labels:
+    env=prod
+    team=billing
+  Annotations:
+    owner=billing@example.com
+`, out.String())
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/output"
+	"github.com/camaeel/kubectl-ctx/internal/pins"
+	"github.com/spf13/cobra"
+)
+
+// listPinned restricts list to the pinned contexts
+var listPinned bool
+
+// newPinCommand returns the pin command
+func newPinCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin CONTEXT_NAME...",
+		Short: "Pin contexts to the top of the selector and completions",
+		Long: `Pin favorite contexts. Pinned contexts are listed at the top of the
+interactive selector, above a separator, and first in shell completions.
+Pins are kept in the tool state, not in the kubeconfig. Pin namespaces with
+"kubectl-ns --pin".`,
+		Example: `  # Pin the contexts used daily
+  kubectl-ctx pin prod staging
+
+  # List them for scripts
+  kubectl-ctx list --pinned`,
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              runPin,
+		ValidArgsFunction: completeContexts(func(p *pins.Pins, name string) bool { return !slices.Contains(p.Contexts, name) }),
+	}
+}
+
+// newUnpinCommand returns the unpin command
+func newUnpinCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unpin CONTEXT_NAME...",
+		Short: "Unpin contexts",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runUnpin,
+		ValidArgsFunction: func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			p, err := pins.Load()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			return p.Contexts, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+		},
+	}
+}
+
+// newListCommand returns the list command
+func newListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List contexts, marking the current one with \"*\"",
+		Long: `List contexts in the order of --sort, marking the current one with "*", like
+kubectl-ctx does when stdout is not a terminal. Without --sort or a sort
+setting, the list is ordered by frecency on a terminal and by name otherwise.
+With --pinned, only the pinned contexts are listed, in the order they were
+pinned. With --selector, only the contexts whose tags match are listed.`,
+		Args: cobra.NoArgs,
+		RunE: runList,
+	}
+
+	cmd.Flags().BoolVar(&listPinned, "pinned", false, "list only the pinned contexts")
+	cmd.Flags().StringVar(&sortOrder, "sort", "", "order of the list: frecency, recent, alpha or cluster (default from the config file, else frecency on a terminal and alpha otherwise)")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "list only the contexts whose tags match, e.g. env=prod,team!=infra")
+
+	return cmd
+}
+
+func runPin(_ *cobra.Command, args []string) error {
+	manager, err := context.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := manager.ValidateContext(name); err != nil {
+			return err
+		}
+	}
+
+	return pins.Update(func(p *pins.Pins) error {
+		for _, name := range args {
+			if p.PinContext(name) {
+				slog.Info("Pinned context", "context", name)
+			} else {
+				slog.Info("Context is already pinned", "context", name)
+			}
+		}
+		return nil
+	})
+}
+
+func runUnpin(_ *cobra.Command, args []string) error {
+	// Contexts that no longer exist can still be unpinned
+	return pins.Update(func(p *pins.Pins) error {
+		for _, name := range args {
+			if p.UnpinContext(name) {
+				slog.Info("Unpinned context", "context", name)
+			} else {
+				slog.Warn("Context is not pinned", "context", name)
+			}
+		}
+		return nil
+	})
+}
+
+func runList(cmd *cobra.Command, _ []string) error {
+	printer, err := output.New(cmd.OutOrStdout(), outputFormat)
+	if err != nil {
+		return err
+	}
+
+	manager, err := context.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+
+	contexts, err := sortedContexts(manager, defaultSortOrder())
+	if err != nil {
+		return err
+	}
+	if listPinned {
+		p, err := pins.Load()
+		if err != nil {
+			return err
+		}
+		contexts, n := pins.First(contexts, p.Contexts)
+		return printer.List(contexts[:n], manager.GetCurrentContext())
+	}
+	return printer.List(contexts, manager.GetCurrentContext())
+}
+
+// completeContexts completes context names accepted by include, pinned
+// contexts first
+func completeContexts(include func(p *pins.Pins, name string) bool) cobra.CompletionFunc {
+	return func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		manager, err := context.NewManager(configFlags)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		// Completions are read by people, so the most likely targets go first
+		contexts, err := sortedContexts(manager, config.SortFrecency)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		p, err := pins.Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		contexts, _ = pins.First(contexts, p.Contexts)
+		if include != nil {
+			contexts = slices.DeleteFunc(contexts, func(name string) bool { return !include(p, name) })
+		}
+		return contexts, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+}
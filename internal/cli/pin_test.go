@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/pins"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// execute runs the binary invoked as argv0 with args and returns stdout
+func execute(t *testing.T, argv0 string, args ...string) (string, error) {
+	t.Helper()
+
+	// setup keeps the loaded config in the shared flags
+	t.Cleanup(func() { configFlags = kubeconfig.NewConfigFlags() })
+
+	var out bytes.Buffer
+	cmd := NewCommand(argv0)
+	cmd.SetOut(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPinContexts(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	kubeconfigPath := testutil.CreateKubeconfig(t, "ctx1", map[string]string{
+		"ctx1": "",
+		"ctx2": "",
+		"ctx3": "",
+		"ctx4": "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	_, err := execute(t, "kubectl-ctx", "pin", "ctx3", "ctx2")
+	require.NoError(t, err)
+	_, err = execute(t, "kubectl-ctx", "pin", "missing")
+	assert.ErrorContains(t, err, `context "missing" not found`)
+
+	out, err := execute(t, "kubectl-ctx", "list", "--pinned")
+	require.NoError(t, err)
+	assert.Equal(t, "  ctx3\n  ctx2\n", out)
+
+	// The full list keeps its order
+	out, err = execute(t, "kubectl-ctx", "list")
+	require.NoError(t, err)
+	assert.Equal(t, "* ctx1\n  ctx2\n  ctx3\n  ctx4\n", out)
+
+	// Completions show pinned contexts first
+	out, err = execute(t, "kubectl-ctx", "__complete", "ctx", "")
+	require.NoError(t, err)
+	assert.Equal(t, "ctx3\nctx2\nctx1\nctx4\n:36\n", out)
+
+	out, err = execute(t, "kubectl-ctx", "__complete", "pin", "")
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1\nctx4\n:36\n", out)
+
+	// Contexts that no longer exist can be unpinned
+	require.NoError(t, pins.Update(func(p *pins.Pins) error {
+		p.PinContext("deleted")
+		return nil
+	}))
+	_, err = execute(t, "kubectl-ctx", "unpin", "ctx3", "deleted")
+	require.NoError(t, err)
+	p, err := pins.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ctx2"}, p.Contexts)
+}
+
+func TestPinNamespaces(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	server := testutil.NewFakeAPIServer(t, []string{"default", "kube-system", "payments", "web"})
+	kubeconfigPath := testutil.CreateKubeconfigWithServer(t, server, "ctx1", map[string]string{
+		"ctx1": "web",
+		"ctx2": "",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	// The current namespace by default
+	_, err := execute(t, "kubectl-ns", "--pin")
+	require.NoError(t, err)
+	_, err = execute(t, "kubectl-ns", "--pin", "payments")
+	require.NoError(t, err)
+	_, err = execute(t, "kubectl-ns", "--context", "ctx2", "--pin", "kube-system")
+	require.NoError(t, err)
+	_, err = execute(t, "kubectl-ns", "--pin", "Invalid")
+	assert.ErrorContains(t, err, "invalid namespace")
+
+	out, err := execute(t, "kubectl-ns", "--pinned")
+	require.NoError(t, err)
+	assert.Equal(t, "* web\n  payments\n", out)
+
+	out, err = execute(t, "kubectl-ns", "__complete", "")
+	require.NoError(t, err)
+	assert.Equal(t, "web\npayments\ndefault\nkube-system\n:36\n", out)
+
+	_, err = execute(t, "kubectl-ns", "--unpin", "web")
+	require.NoError(t, err)
+	out, err = execute(t, "kubectl-ns", "--pinned")
+	require.NoError(t, err)
+	assert.Equal(t, "  payments\n", out)
+
+	out, err = execute(t, "kubectl-ns", "--context", "ctx2", "--pinned")
+	require.NoError(t, err)
+	assert.Equal(t, "  kube-system\n", out)
+
+	_, err = execute(t, "kubectl-ns", "--pin", "--unpin")
+	assert.ErrorContains(t, err, "none of the others can be")
+}
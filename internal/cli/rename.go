@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/camaeel/kubectl-ctx/internal/context"
+	"github.com/camaeel/kubectl-ctx/internal/pins"
+	"github.com/camaeel/kubectl-ctx/internal/usage"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// newRenameCommand returns the rename command
+func newRenameCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename [FILE:]CONTEXT_NAME NEW_NAME",
+		Short: "Rename a context in the file that defines it",
+		Long: `Rename a context in the kubeconfig file that defines it, moving
+current-context along if it points at the context. Its usage history and pin
+follow the new name.
+
+FILE:CONTEXT_NAME (the file's path or base name) renames the definition in that
+file, such as one shadowed by a context of the same name in an earlier
+KUBECONFIG file, which makes it reachable by kubectl. Its cluster and user,
+when shadowed as well, are copied under the new name.`,
+		Example: `  # Rename a context
+  kubectl-ctx rename arn:aws:eks:eu-west-1:123456789012:cluster/prod prod
+
+  # Make the shared-context of file2.yaml reachable
+  kubectl-ctx rename file2.yaml:shared-context shared-context-2`,
+		Args: cobra.ExactArgs(2),
+		RunE: runRename,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeContexts(nil)(cmd, args, toComplete)
+		},
+	}
+}
+
+// newExportCommand returns the export command
+func newExportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export [FILE:]CONTEXT_NAME",
+		Short: "Print a self-contained kubeconfig for a context",
+		Long: `Print a kubeconfig with only a context, its cluster and its user, with the
+files they reference embedded, like "kubectl config view --minify --flatten".
+The output includes credentials.
+
+FILE:CONTEXT_NAME (the file's path or base name) exports the definitions of that
+file, even when a context of the same name in an earlier KUBECONFIG file
+shadows them.`,
+		Example: `  # Hand over access to a cluster
+  kubectl-ctx export prod > prod.kubeconfig
+
+  # Export the shadowed shared-context of file2.yaml
+  kubectl-ctx export file2.yaml:shared-context`,
+		Args:              cobra.ExactArgs(1),
+		RunE:              runExport,
+		ValidArgsFunction: completeContexts(nil),
+	}
+}
+
+func runRename(_ *cobra.Command, args []string) error {
+	manager, err := context.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+
+	oldName, newName := args[0], args[1]
+	if manager.ValidateContext(oldName) == nil {
+		if err := renameContext(manager, oldName, newName); err != nil {
+			return err
+		}
+		slog.Info("Renamed context", "context", oldName, "name", newName)
+		return nil
+	}
+
+	ref, ok, err := manager.ParseFileRef(oldName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return manager.ValidateContext(oldName)
+	}
+	if err := manager.RenameFileContext(ref, newName); err != nil {
+		return err
+	}
+	slog.Info("Renamed context", "context", ref.Name, "file", ref.File, "name", newName)
+	return nil
+}
+
+// renameContext renames a context along with its usage history and pin
+func renameContext(manager *context.Manager, oldName, newName string) error {
+	if err := manager.RenameContext(oldName, newName); err != nil {
+		return err
+	}
+	if err := usage.Rename(oldName, newName); err != nil {
+		slog.Debug("Failed to rename context usage", "error", err)
+	}
+	err := pins.Update(func(p *pins.Pins) error {
+		p.RenameContext(oldName, newName)
+		return nil
+	})
+	if err != nil {
+		slog.Debug("Failed to rename context pins", "error", err)
+	}
+	return nil
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	manager, err := context.NewManager(configFlags)
+	if err != nil {
+		return err
+	}
+
+	config, err := manager.Export(args[0])
+	if err != nil {
+		return err
+	}
+	content, err := clientcmd.Write(*config)
+	if err != nil {
+		return fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	_, err = cmd.OutOrStdout().Write(content)
+	return err
+}
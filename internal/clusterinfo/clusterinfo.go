@@ -0,0 +1,301 @@
+// Package clusterinfo fetches the server version and node count of contexts
+// in the background and caches them on disk
+package clusterinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/credentials"
+	"github.com/camaeel/kubectl-ctx/internal/utils/paths"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// DefaultTimeout bounds fetching the info of one context
+	DefaultTimeout = 5 * time.Second
+	// DefaultTTL is how long cached info is used before it is refreshed
+	DefaultTTL = time.Hour
+	// DefaultConcurrency is the number of contexts fetched at once
+	DefaultConcurrency = 8
+
+	cacheName = "clusterinfo.json"
+)
+
+// Info describes the cluster of a context
+type Info struct {
+	// Server is the server the info was fetched from; cached info is
+	// discarded when the context points elsewhere
+	Server        string `json:"server"`
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// Nodes is nil when nodes can't be listed, e.g. for lack of permissions
+	Nodes *int   `json:"nodes,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Skipped is the credential method of a context whose cluster was not
+	// contacted, as its credential plugin might start a login
+	Skipped string    `json:"skipped,omitempty"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// String renders the info for the context selector
+func (i Info) String() string {
+	switch {
+	case i.Skipped != "":
+		return i.Skipped + " credentials"
+	case i.Error != "":
+		return "unreachable"
+	case i.Nodes == nil:
+		return i.ServerVersion
+	case *i.Nodes == 1:
+		return i.ServerVersion + ", 1 node"
+	default:
+		return fmt.Sprintf("%s, %d nodes", i.ServerVersion, *i.Nodes)
+	}
+}
+
+// Fetch requests the server version and node list of a context. Contexts
+// whose user runs an exec plugin or auth-provider are skipped: the info is
+// fetched for every context, and these could start logins to clusters the
+// user never selects.
+func Fetch(ctx context.Context, config api.Config, contextName string, overrides *clientcmd.ConfigOverrides) Info {
+	info := Info{Fetched: time.Now()}
+
+	clientConfig := clientcmd.NewNonInteractiveClientConfig(config, contextName, overrides, nil)
+	raw, err := clientConfig.MergedRawConfig()
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	if c, exists := raw.Contexts[raw.CurrentContext]; exists {
+		if method := credentials.Method(raw.AuthInfos[c.AuthInfo]); method != credentials.MethodStatic {
+			info.Skipped = method
+			return info
+		}
+	}
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		restConfig.Timeout = time.Until(deadline)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.ServerVersion = version.GitVersion
+
+	count, err := countNodes(ctx, restConfig)
+	if err != nil {
+		slog.Debug("Failed to list nodes", "context", contextName, "error", err)
+		return info
+	}
+	info.Nodes = &count
+	return info
+}
+
+// countNodes counts the nodes of a cluster from the metadata of a single
+// node and the remaining item count. Servers that don't report the count
+// list the metadata of all nodes instead.
+func countNodes(ctx context.Context, restConfig *rest.Config) (int, error) {
+	client, err := metadata.NewForConfig(restConfig)
+	if err != nil {
+		return 0, err
+	}
+	nodes := client.Resource(corev1.SchemeGroupVersion.WithResource("nodes"))
+
+	list, err := nodes.List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	if list.RemainingItemCount != nil {
+		return len(list.Items) + int(*list.RemainingItemCount), nil
+	}
+	if list.Continue == "" {
+		return len(list.Items), nil
+	}
+
+	// Served from the API server's watch cache, which is cheap for large clusters
+	list, err = nodes.List(ctx, metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		return 0, err
+	}
+	return len(list.Items), nil
+}
+
+// Fetcher fetches the info of one context
+type Fetcher func(ctx context.Context, contextName string) Info
+
+// Collector fetches the info of many contexts concurrently. Cached info is
+// available right away and refreshed when it is older than TTL.
+type Collector struct {
+	// Path is the cache file
+	Path        string
+	TTL         time.Duration
+	Timeout     time.Duration
+	Concurrency int
+
+	mu      sync.Mutex
+	infos   map[string]Info
+	pending map[string]bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewCollector returns a collector using the cache in the tool's cache
+// directory
+func NewCollector() (*Collector, error) {
+	dir, err := paths.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Collector{
+		Path:        filepath.Join(dir, cacheName),
+		TTL:         DefaultTTL,
+		Timeout:     DefaultTimeout,
+		Concurrency: DefaultConcurrency,
+	}, nil
+}
+
+// Start loads the cache and fetches the info of every context whose cached
+// info is missing, stale or for another server in the background. servers
+// maps context names to their server.
+func (c *Collector) Start(servers map[string]string, fetch Fetcher) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.infos = c.load()
+	c.pending = map[string]bool{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	sem := make(chan struct{}, max(c.Concurrency, 1))
+
+	for name, server := range servers {
+		if info, ok := c.infos[name]; ok && info.Server != server {
+			delete(c.infos, name)
+		} else if ok && info.Error == "" && time.Since(info.Fetched) < c.TTL {
+			continue
+		}
+
+		c.pending[name] = true
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			fetchCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+			info := fetch(fetchCtx, name)
+			info.Server = server
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			delete(c.pending, name)
+			// Results of contexts abandoned by Stop are not cached
+			if ctx.Err() == nil {
+				c.infos[name] = info
+			}
+		}()
+	}
+}
+
+// Get returns the info of a context, which may be cached while a refresh is
+// pending. ok is false when no info is available yet.
+func (c *Collector) Get(name string) (info Info, pending, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, ok = c.infos[name]
+	return info, c.pending[name], ok
+}
+
+// Describe renders the info of a context for the context selector
+func (c *Collector) Describe(name string) string {
+	info, pending, ok := c.Get(name)
+	switch {
+	case ok:
+		return info.String()
+	case pending:
+		return "loading..."
+	default:
+		return ""
+	}
+}
+
+// Wait blocks until all fetches are done
+func (c *Collector) Wait() {
+	c.wg.Wait()
+}
+
+// Stop abandons pending fetches without waiting for them and saves the info
+// fetched so far
+func (c *Collector) Stop() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save()
+}
+
+// load reads the cache, ignoring a missing or corrupt file
+func (c *Collector) load() map[string]Info {
+	infos := map[string]Info{}
+
+	content, err := os.ReadFile(c.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return infos
+	}
+	if err == nil {
+		err = json.Unmarshal(content, &infos)
+	}
+	if err != nil {
+		slog.Debug("Ignoring cluster info cache", "file", c.Path, "error", err)
+		return map[string]Info{}
+	}
+	return infos
+}
+
+func (c *Collector) save() error {
+	content, err := json.MarshalIndent(c.infos, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.Path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write cluster info cache: %w", err)
+	}
+	return nil
+}
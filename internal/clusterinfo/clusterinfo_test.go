@@ -0,0 +1,161 @@
+package clusterinfo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestFetch(t *testing.T) {
+	config := api.NewConfig()
+	config.Clusters["up"] = &api.Cluster{Server: testutil.NewFakeAPIServer(t, nil)}
+	config.Clusters["down"] = &api.Cluster{Server: "http://127.0.0.1:1"}
+	config.AuthInfos["user"] = &api.AuthInfo{}
+	config.Contexts["up"] = &api.Context{Cluster: "up", AuthInfo: "user"}
+	config.Contexts["down"] = &api.Context{Cluster: "down", AuthInfo: "user"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	info := Fetch(ctx, *config, "up", &clientcmd.ConfigOverrides{})
+	assert.Empty(t, info.Error)
+	assert.Equal(t, "v1.36.3", info.ServerVersion)
+	require.NotNil(t, info.Nodes)
+	assert.Equal(t, testutil.FakeNodes, *info.Nodes)
+	assert.Equal(t, "v1.36.3, 3 nodes", info.String())
+
+	info = Fetch(ctx, *config, "down", &clientcmd.ConfigOverrides{})
+	assert.NotEmpty(t, info.Error)
+	assert.Equal(t, "unreachable", info.String())
+}
+
+func TestFetch_SkipsCredentialPlugins(t *testing.T) {
+	plugin, calls := testutil.NewExecPlugin(t, "token")
+	config := api.NewConfig()
+	config.Clusters["eks"] = &api.Cluster{Server: testutil.NewFakeAPIServer(t, nil)}
+	config.AuthInfos["exec"] = &api.AuthInfo{Exec: &api.ExecConfig{
+		APIVersion:      "client.authentication.k8s.io/v1",
+		Command:         plugin,
+		InteractiveMode: api.NeverExecInteractiveMode,
+	}}
+	config.AuthInfos["static"] = &api.AuthInfo{Token: "token"}
+	config.Contexts["eks"] = &api.Context{Cluster: "eks", AuthInfo: "exec"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	info := Fetch(ctx, *config, "eks", &clientcmd.ConfigOverrides{})
+	assert.Equal(t, "exec", info.Skipped)
+	assert.Empty(t, info.ServerVersion)
+	assert.Equal(t, "exec credentials", info.String())
+	assert.NoFileExists(t, calls, "the plugin must not run")
+
+	// --user selects the user the cluster is contacted with
+	info = Fetch(ctx, *config, "eks", &clientcmd.ConfigOverrides{Context: api.Context{AuthInfo: "static"}})
+	assert.Empty(t, info.Skipped)
+	assert.Equal(t, "v1.36.3", info.ServerVersion)
+}
+
+func TestCountNodes_WithoutRemainingCount(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		list := `{"kind":"NodeList","apiVersion":"v1","metadata":{"continue":"next"},"items":[{"metadata":{"name":"a"}}]}`
+		if r.URL.Query().Get("limit") == "" {
+			list = `{"kind":"NodeList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"a"}},{"metadata":{"name":"b"}}]}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(list))
+	}))
+	t.Cleanup(server.Close)
+
+	count, err := countNodes(context.Background(), &rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"limit=1", "resourceVersion=0"}, requests)
+}
+
+func TestInfoString(t *testing.T) {
+	one := 1
+	assert.Equal(t, "v1.30.0", Info{ServerVersion: "v1.30.0"}.String())
+	assert.Equal(t, "v1.30.0, 1 node", Info{ServerVersion: "v1.30.0", Nodes: &one}.String())
+}
+
+func TestCollector(t *testing.T) {
+	c := &Collector{
+		Path:        filepath.Join(t.TempDir(), "cache", cacheName),
+		TTL:         time.Hour,
+		Timeout:     time.Second,
+		Concurrency: 2,
+	}
+
+	var mu sync.Mutex
+	var fetched []string
+	nodes := 5
+	fetch := func(_ context.Context, name string) Info {
+		mu.Lock()
+		defer mu.Unlock()
+		fetched = append(fetched, name)
+		return Info{ServerVersion: "v1.30.0", Nodes: &nodes, Fetched: time.Now()}
+	}
+
+	c.Start(map[string]string{"a": "https://a", "b": "https://b"}, fetch)
+	c.Wait()
+	assert.ElementsMatch(t, []string{"a", "b"}, fetched)
+	assert.Equal(t, "v1.30.0, 5 nodes", c.Describe("a"))
+	assert.Empty(t, c.Describe("unknown"))
+	require.NoError(t, c.Stop())
+	assert.FileExists(t, c.Path)
+
+	// Fresh cached info is used as is, info for another server is refetched
+	fetched = nil
+	c.Start(map[string]string{"a": "https://a", "b": "https://other"}, fetch)
+	c.Wait()
+	assert.Equal(t, []string{"b"}, fetched)
+	require.NoError(t, c.Stop())
+
+	// Stale info is shown while it is refreshed
+	c.TTL = 0
+	fetched = nil
+	release := make(chan struct{})
+	c.Start(map[string]string{"a": "https://a"}, func(ctx context.Context, name string) Info {
+		<-release
+		return fetch(ctx, name)
+	})
+	info, pending, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.True(t, pending)
+	assert.Equal(t, "v1.30.0", info.ServerVersion)
+	close(release)
+	c.Wait()
+	assert.Equal(t, []string{"a"}, fetched)
+}
+
+func TestCollector_Loading(t *testing.T) {
+	c := &Collector{Path: filepath.Join(t.TempDir(), cacheName), TTL: time.Hour, Timeout: time.Second}
+
+	release := make(chan struct{})
+	c.Start(map[string]string{"a": "https://a"}, func(ctx context.Context, _ string) Info {
+		<-release
+		return Info{Error: "abandoned"}
+	})
+	assert.Equal(t, "loading...", c.Describe("a"))
+
+	// Results arriving after Stop are not cached
+	require.NoError(t, c.Stop())
+	close(release)
+	c.Wait()
+	_, _, ok := c.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,213 @@
+// Package config loads the tool's configuration file
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/utils/paths"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FileName is the name of the configuration file in the config directory
+const FileName = "config.yaml"
+
+// Environment variables overriding settings of the configuration file
+const (
+	EnvSort           = "KUBECTL_CTX_SORT"
+	EnvPageSize       = "KUBECTL_CTX_PAGE_SIZE"
+	EnvProtected      = "KUBECTL_CTX_PROTECTED"
+	EnvClusterInfoTTL = "KUBECTL_CTX_CLUSTER_INFO_TTL"
+)
+
+// Version is the newest configuration file version this build reads. Files
+// without a version are read as version 1.
+const Version = 1
+
+// Config is the tool's configuration
+type Config struct {
+	// Version is the schema version of the file
+	Version int `json:"version,omitempty"`
+	// KubeconfigDirs are glob patterns of kubeconfig files merged into the
+	// loading precedence after KUBECONFIG (or ~/.kube/config)
+	KubeconfigDirs []string `json:"kubeconfigDirs,omitempty"`
+	// KubeconfigFiles are files added to or removed from the loading
+	// precedence with "files enable" and "files disable"
+	KubeconfigFiles FileSet `json:"kubeconfigFiles,omitzero"`
+	// Sort is the default order of the context list and selector
+	Sort string `json:"sort,omitempty"`
+	// Picker configures the interactive selector
+	Picker Picker `json:"picker,omitzero"`
+	// Protected are path.Match patterns of contexts that can't be renamed or
+	// deleted in the selector, and whose selection is warned about
+	Protected []string `json:"protected,omitempty"`
+	// Aliases map short names to [FILE:]CONTEXT[/NAMESPACE] targets
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Tags maps context names, or path.Match patterns of them, to the tags
+	// selected with --selector. Tags set in the kubeconfig override them.
+	Tags map[string]map[string]string `json:"tags,omitempty"`
+	// Cache configures how long cached data is used
+	Cache Cache `json:"cache,omitzero"`
+	// Hooks are executables run before and after every switch
+	Hooks Hooks `json:"hooks,omitzero"`
+	// Colors style contexts in the selector; the first matching rule wins
+	Colors []ColorRule `json:"colors,omitempty"`
+}
+
+// Picker configures the interactive selector
+type Picker struct {
+	// PageSize limits the number of listed items; 0 fills the terminal
+	PageSize int `json:"pageSize,omitempty"`
+}
+
+// Cache configures cache lifetimes
+type Cache struct {
+	// ClusterInfo is how long the server version and node count shown by
+	// --info are used before they are refreshed
+	ClusterInfo *metav1.Duration `json:"clusterInfo,omitempty"`
+}
+
+// Hooks lists hook executables per phase, run before the hooks discovered
+// on PATH
+type Hooks struct {
+	Pre  []string `json:"pre,omitempty"`
+	Post []string `json:"post,omitempty"`
+}
+
+// ColorRule colors the contexts matching both its context pattern and its
+// tag selector, when set
+type ColorRule struct {
+	// Context is a path.Match pattern of context names
+	Context string `json:"context,omitempty"`
+	// Selector is a label selector of context tags
+	Selector string `json:"selector,omitempty"`
+	// Color is one of Colors
+	Color string `json:"color"`
+}
+
+// Colors maps the color names of color rules to their ANSI sequence
+var Colors = map[string]string{
+	"black":   "\033[30m",
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+	"white":   "\033[37m",
+}
+
+// IsProtected reports whether a context matches a protected pattern
+func (c *Config) IsProtected(name string) bool {
+	return slices.ContainsFunc(c.Protected, func(pattern string) bool {
+		matched, _ := path.Match(pattern, name)
+		return matched
+	})
+}
+
+// FileSet lists kubeconfig files by absolute path
+type FileSet struct {
+	// Enabled files are loaded after all others
+	Enabled []string `json:"enabled,omitempty"`
+	// Disabled files are not loaded, even when they are in KUBECONFIG or
+	// discovered
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+// Enable adds path to the enabled files and removes it from the disabled
+// ones. add is false for files that are loaded anyway, which only need to
+// be re-enabled.
+func (s *FileSet) Enable(path string, add bool) {
+	s.Disabled = slices.DeleteFunc(s.Disabled, func(p string) bool { return p == path })
+	if add && !slices.Contains(s.Enabled, path) {
+		s.Enabled = append(s.Enabled, path)
+	}
+}
+
+// Disable adds path to the disabled files and removes it from the enabled
+// ones
+func (s *FileSet) Disable(path string) {
+	s.Enabled = slices.DeleteFunc(s.Enabled, func(p string) bool { return p == path })
+	if !slices.Contains(s.Disabled, path) {
+		s.Disabled = append(s.Disabled, path)
+	}
+}
+
+// Path returns the location of the configuration file
+func Path() (string, error) {
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the configuration file and applies the environment variable
+// overrides. A missing file is an empty configuration.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	c, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFile reads a configuration file, validating it against the schema
+func LoadFile(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return parse(path, content)
+}
+
+// applyEnv overrides settings with the environment variables that are set
+func (c *Config) applyEnv() error {
+	if env := os.Getenv(EnvSort); env != "" {
+		if err := ValidateSortOrder(env); err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvSort, err)
+		}
+		c.Sort = env
+	}
+	if env := os.Getenv(EnvPageSize); env != "" {
+		size, err := strconv.Atoi(env)
+		if err != nil || size < 0 {
+			return fmt.Errorf("invalid %s %q, expected a non-negative integer", EnvPageSize, env)
+		}
+		c.Picker.PageSize = size
+	}
+	if env := os.Getenv(EnvProtected); env != "" {
+		c.Protected = strings.Split(env, ",")
+		for _, pattern := range c.Protected {
+			if err := validatePattern(pattern); err != nil {
+				return fmt.Errorf("invalid %s: %w", EnvProtected, err)
+			}
+		}
+	}
+	if env := os.Getenv(EnvClusterInfoTTL); env != "" {
+		ttl, err := time.ParseDuration(env)
+		if err != nil || ttl < 0 {
+			return fmt.Errorf("invalid %s %q, expected a duration such as 1h30m", EnvClusterInfoTTL, env)
+		}
+		c.Cache.ClusterInfo = &metav1.Duration{Duration: ttl}
+	}
+	return nil
+}
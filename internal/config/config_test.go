@@ -0,0 +1,262 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	c, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, c.KubeconfigDirs)
+
+	path, err := Path()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "kubectl-ctx", FileName), path)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte("kubeconfigDirs:\n- ~/.kube/configs/*.yaml\n"), 0600))
+	c, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"~/.kube/configs/*.yaml"}, c.KubeconfigDirs)
+
+	require.NoError(t, os.WriteFile(path, []byte("kubeconfigDir: ~/.kube\n"), 0600))
+	_, err = Load()
+	assert.ErrorContains(t, err, "kubeconfigDir")
+}
+
+func TestFileSet(t *testing.T) {
+	var s FileSet
+	s.Enable("/a", true)
+	s.Enable("/a", true)
+	s.Disable("/b")
+	assert.Equal(t, FileSet{Enabled: []string{"/a"}, Disabled: []string{"/b"}}, s)
+
+	s.Disable("/a")
+	s.Enable("/b", false)
+	assert.Equal(t, FileSet{Enabled: []string{}, Disabled: []string{"/a"}}, s)
+}
+
+func TestUpdate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	require.NoError(t, Update(func(c *Config) error {
+		c.KubeconfigFiles.Disable("/a")
+		return nil
+	}))
+
+	c, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/a"}, c.KubeconfigFiles.Disabled)
+
+	path, err := Path()
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1\nkubeconfigFiles:\n  disabled:\n    - /a\n", string(content))
+	assert.NoFileExists(t, path+".lock")
+}
+
+func TestUpdate_KeepsFormatting(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := Path()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(`version: 1
+# Production contexts
+protected: &prod
+  - prod-*
+sort: alpha # most used first is confusing
+kubeconfigFiles:
+  enabled:
+    - /b
+`), 0600))
+
+	require.NoError(t, Update(func(c *Config) error {
+		c.KubeconfigFiles.Disable("/b")
+		return nil
+	}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `version: 1
+# Production contexts
+protected: &prod
+  - prod-*
+sort: alpha # most used first is confusing
+kubeconfigFiles:
+  disabled:
+    - /b
+`, string(content))
+}
+
+func TestLoadFile_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "unknown field",
+			content: "version: 1\npicker:\n  pagesize: 10\n",
+			want:    []string{":3:3: picker.pagesize: unknown field, expected one of pageSize"},
+		},
+		{
+			name:    "wrong kind",
+			content: "protected: prod-*\n",
+			want:    []string{":1:12: protected: expected a list, got a string"},
+		},
+		{
+			name:    "unquoted tag value",
+			content: "tags:\n  prod-*:\n    critical: true\n",
+			want:    []string{`:3:15: tags."prod-*".critical: expected a string, got a boolean; quote it`},
+		},
+		{
+			name:    "invalid duration",
+			content: "cache:\n  clusterInfo: 1 hour\n",
+			want:    []string{`:2:16: cache.clusterInfo: invalid duration "1 hour"`},
+		},
+		{
+			name:    "newer version",
+			content: "version: 2\n",
+			want:    []string{":1:10: version: unsupported version 2, expected 1 or older"},
+		},
+		{
+			name: "several values",
+			content: `sort: newest
+picker:
+  pageSize: -1
+aliases:
+  prod/web: prod
+tags:
+  "[": {env: prod}
+colors:
+- context: prod-*
+  color: orange
+- color: red
+`,
+			want: []string{
+				`:1:7: sort: invalid sort order "newest"`,
+				":3:13: picker.pageSize: must not be negative",
+				`:5:3: aliases.prod/web: invalid alias name`,
+				`:7:3: tags."[": invalid pattern "["`,
+				`:10:10: colors[0].color: invalid color "orange", expected one of black, blue`,
+				":11:3: colors[1]: set context, selector or both",
+			},
+		},
+		{
+			name: "schema and values",
+			content: `sort: newest
+picker:
+  pageSize: ten
+aliases:
+  prod/web: prod
+protected: [prod-*, {name: prod}]
+colors:
+- context: prod-*
+  color: orange
+  style: bold
+`,
+			want: []string{
+				`:1:7: sort: invalid sort order "newest"`,
+				":3:13: picker.pageSize: expected an integer, got a string",
+				`:5:3: aliases.prod/web: invalid alias name`,
+				":6:21: protected[1]: expected a string, got a mapping",
+				":10:3: colors[0].style: unknown field",
+			},
+		},
+		{
+			name:    "syntax error",
+			content: "sort: [alpha\n",
+			want:    []string{"yaml: line 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			_, err := LoadFile(path)
+			require.Error(t, err)
+			lines := strings.Split(err.Error(), "\n")
+			require.Len(t, lines, len(tt.want), err.Error())
+			for i, want := range tt.want {
+				assert.Contains(t, lines[i], "invalid config "+path)
+				assert.Contains(t, lines[i], want)
+			}
+		})
+	}
+}
+
+func TestLoadFile_Full(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	content := `version: 1
+sort: recent
+picker:
+  pageSize: 15
+protected: ["prod-*"]
+aliases:
+  pay: prod-payments/payments
+tags:
+  prod-*: {env: prod}
+cache:
+  clusterInfo: 30m
+hooks:
+  post: [~/bin/notify]
+colors:
+- selector: env=prod
+  color: red
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	c, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 15, c.Picker.PageSize)
+	assert.True(t, c.IsProtected("prod-eu"))
+	assert.False(t, c.IsProtected("staging"))
+	assert.Equal(t, "prod-payments/payments", c.Aliases["pay"])
+	assert.Equal(t, 30*time.Minute, c.Cache.ClusterInfo.Duration)
+	assert.Equal(t, []string{"~/bin/notify"}, c.Hooks.Post)
+	assert.Equal(t, []ColorRule{{Selector: "env=prod", Color: "red"}}, c.Colors)
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	require.NoError(t, Update(func(c *Config) error {
+		c.Sort = "alpha"
+		c.Picker.PageSize = 10
+		return nil
+	}))
+
+	t.Setenv(EnvSort, "recent")
+	t.Setenv(EnvProtected, "prod-*,live")
+	t.Setenv(EnvClusterInfoTTL, "5m")
+	c, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "recent", c.Sort)
+	assert.Equal(t, 10, c.Picker.PageSize)
+	assert.Equal(t, []string{"prod-*", "live"}, c.Protected)
+	assert.Equal(t, 5*time.Minute, c.Cache.ClusterInfo.Duration)
+
+	// Overrides aren't written back to the file
+	path, err := Path()
+	require.NoError(t, err)
+	c, err = LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", c.Sort)
+	assert.Equal(t, Version, c.Version)
+
+	t.Setenv(EnvPageSize, "many")
+	_, err = Load()
+	assert.ErrorContains(t, err, EnvPageSize)
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"github.com/camaeel/kubectl-ctx/internal/utils/atomicfile"
+	"github.com/camaeel/kubectl-ctx/internal/utils/filelock"
+	"go.yaml.in/yaml/v3"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Update applies mutate to the configuration file under its lock. Only the
+// settings mutate changes are rewritten, so the comments, ordering and
+// anchors of the rest of the file are kept.
+func Update(mutate func(c *Config) error) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	unlock, err := filelock.Lock([]string{path})
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	c := &Config{}
+	if len(bytes.TrimSpace(content)) > 0 {
+		if c, err = parse(path, content); err != nil {
+			return err
+		}
+	}
+
+	before, err := settings(c)
+	if err != nil {
+		return err
+	}
+	if err := mutate(c); err != nil {
+		return err
+	}
+	c.Version = Version
+	after, err := settings(c)
+	if err != nil {
+		return err
+	}
+
+	content, err = editSettings(content, before, after)
+	if err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+
+	if err := atomicfile.Write(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// ErrChanged is returned by Replace when the configuration file changed
+// since it was read
+var ErrChanged = errors.New("config file changed meanwhile")
+
+// Replace writes content as the configuration file under its lock, provided
+// it still holds original (nil when it didn't exist), so concurrent updates
+// aren't lost
+func Replace(original, content []byte) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := filelock.Lock([]string{path})
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if !bytes.Equal(current, original) {
+		return ErrChanged
+	}
+
+	if err := atomicfile.Write(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// settings returns the top-level settings of c as they are serialized
+func settings(c *Config) (map[string]any, error) {
+	content, err := sigsyaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var s map[string]any
+	if err := sigsyaml.Unmarshal(content, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// editSettings replaces the top-level settings of the document content that
+// differ between before and after, leaving the other nodes untouched
+func editSettings(content []byte, before, after map[string]any) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		*root = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	keys := slices.Concat(slices.Collect(maps.Keys(before)), slices.Collect(maps.Keys(after)))
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	for _, key := range keys {
+		if reflect.DeepEqual(before[key], after[key]) {
+			continue
+		}
+
+		i := keyIndex(root, key)
+		value, ok := after[key]
+		if !ok {
+			if i >= 0 {
+				root.Content = slices.Delete(root.Content, i, i+2)
+			}
+			continue
+		}
+
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return nil, err
+		}
+		switch {
+		case i >= 0:
+			root.Content[i+1] = &node
+		case key == "version":
+			// The version heads the file
+			root.Content = slices.Insert(root.Content, 0, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &node)
+		default:
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &node)
+		}
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// keyIndex returns the index of key in the content of a mapping node, which
+// alternates keys and values, or -1 if it isn't set
+func keyIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
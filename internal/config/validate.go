@@ -0,0 +1,478 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Sort orders of contexts, as implemented by the context package
+const (
+	// SortFrecency ranks contexts switched to often and recently first
+	SortFrecency = "frecency"
+	// SortRecent ranks the most recently switched to contexts first
+	SortRecent = "recent"
+	// SortAlpha sorts contexts by name
+	SortAlpha = "alpha"
+	// SortCluster groups contexts by the cluster they use
+	SortCluster = "cluster"
+)
+
+// SortOrders are the valid sort orders
+var SortOrders = []string{SortFrecency, SortRecent, SortAlpha, SortCluster}
+
+// ValidateSortOrder checks that order is one of SortOrders
+func ValidateSortOrder(order string) error {
+	if !slices.Contains(SortOrders, order) {
+		return fmt.Errorf("invalid sort order %q, must be one of %s", order, strings.Join(SortOrders, ", "))
+	}
+	return nil
+}
+
+// Error is a schema violation, located in the configuration file
+type Error struct {
+	File   string
+	Line   int
+	Column int
+	// Field is the path of the offending field, e.g. colors[0].color
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location += fmt.Sprintf(":%d:%d", e.Line, e.Column)
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("invalid config %s: %s", location, e.Message)
+	}
+	return fmt.Sprintf("invalid config %s: %s: %s", location, e.Field, e.Message)
+}
+
+// parse decodes the content of the configuration file, reporting every
+// schema violation with its location
+func parse(file string, content []byte) (*Config, error) {
+	c := &Config{}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", file, err)
+	}
+	if len(doc.Content) == 0 {
+		return c, nil
+	}
+
+	v := &validator{file: file, root: doc.Content[0], invalid: map[*yaml.Node]bool{}}
+	v.check(v.root, reflect.TypeFor[Config](), nil)
+
+	// Check the values of whatever matches the schema, so that all errors
+	// are reported at once
+	if valid := v.valid(v.root); valid != nil {
+		content, err := yaml.Marshal(valid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", file, err)
+		}
+		if err := sigsyaml.Unmarshal(content, c); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", file, err)
+		}
+		c.validate(v)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// validate checks the values of a decoded configuration
+func (c *Config) validate(v *validator) {
+	if c.Version < 0 || c.Version > Version {
+		v.fail(fieldPath{"version"}, "unsupported version %d, expected %d or older", c.Version, Version)
+	}
+	if err := ValidateSortOrder(c.Sort); c.Sort != "" && err != nil {
+		v.fail(fieldPath{"sort"}, "%v", err)
+	}
+	if c.Picker.PageSize < 0 {
+		v.fail(fieldPath{"picker", "pageSize"}, "must not be negative")
+	}
+	for i, pattern := range c.Protected {
+		if err := validatePattern(pattern); err != nil {
+			v.fail(fieldPath{"protected", i}, "%v", err)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Aliases)) {
+		p := fieldPath{"aliases", name}
+		if name == "" || strings.ContainsAny(name, "/:") {
+			v.failKey(p, "invalid alias name, it must not be empty or contain / or :")
+		}
+		if c.Aliases[name] == "" {
+			v.fail(p, "empty target, expected [FILE:]CONTEXT[/NAMESPACE]")
+		}
+	}
+
+	for _, pattern := range slices.Sorted(maps.Keys(c.Tags)) {
+		p := fieldPath{"tags", pattern}
+		if err := validatePattern(pattern); err != nil {
+			v.failKey(p, "%v", err)
+		}
+		tags := c.Tags[pattern]
+		for _, key := range slices.Sorted(maps.Keys(tags)) {
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				v.failKey(p.key(key), "invalid tag name: %s", strings.Join(errs, "; "))
+			}
+			if errs := validation.IsValidLabelValue(tags[key]); len(errs) > 0 {
+				v.fail(p.key(key), "invalid tag value: %s", strings.Join(errs, "; "))
+			}
+		}
+	}
+
+	for phase, hooks := range map[string][]string{"pre": c.Hooks.Pre, "post": c.Hooks.Post} {
+		for i, hook := range hooks {
+			if hook == "" {
+				v.fail(fieldPath{"hooks", phase, i}, "empty path")
+			}
+		}
+	}
+
+	for i, rule := range c.Colors {
+		p := fieldPath{"colors", i}
+		if rule.Context == "" && rule.Selector == "" {
+			v.fail(p, "set context, selector or both")
+		}
+		if rule.Context != "" {
+			if err := validatePattern(rule.Context); err != nil {
+				v.fail(p.key("context"), "%v", err)
+			}
+		}
+		if rule.Selector != "" {
+			if _, err := labels.Parse(rule.Selector); err != nil {
+				v.fail(p.key("selector"), "invalid selector: %v", err)
+			}
+		}
+		if _, ok := Colors[rule.Color]; !ok {
+			v.fail(p.key("color"), "invalid color %q, expected one of %s",
+				rule.Color, strings.Join(slices.Sorted(maps.Keys(Colors)), ", "))
+		}
+	}
+}
+
+func validatePattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// fieldPath locates a value in the configuration: mapping keys are strings,
+// sequence indexes ints
+type fieldPath []any
+
+func (p fieldPath) key(k string) fieldPath {
+	return append(p[:len(p):len(p)], k)
+}
+
+func (p fieldPath) index(i int) fieldPath {
+	return append(p[:len(p):len(p)], i)
+}
+
+func (p fieldPath) String() string {
+	var sb strings.Builder
+	for _, elem := range p {
+		switch elem := elem.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", elem)
+		case string:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			if strings.ContainsAny(elem, ".[]*?\\ ") || elem == "" {
+				elem = strconv.Quote(elem)
+			}
+			sb.WriteString(elem)
+		}
+	}
+	return sb.String()
+}
+
+// validator collects the schema violations of a document
+type validator struct {
+	file string
+	root *yaml.Node
+	errs []*Error
+	// invalid holds the nodes violations were recorded at
+	invalid map[*yaml.Node]bool
+}
+
+// at records a violation at node n
+func (v *validator) at(n *yaml.Node, p fieldPath, format string, args ...any) {
+	v.invalid[n] = true
+	v.errs = append(v.errs, &Error{
+		File:    v.file,
+		Line:    n.Line,
+		Column:  n.Column,
+		Field:   p.String(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// fail records a violation of the value at p
+func (v *validator) fail(p fieldPath, format string, args ...any) {
+	v.at(v.lookup(p, false), p, format, args...)
+}
+
+// failKey records a violation of the mapping key ending p
+func (v *validator) failKey(p fieldPath, format string, args ...any) {
+	v.at(v.lookup(p, true), p, format, args...)
+}
+
+// lookup returns the node at p, or its key node, falling back to the
+// closest parent present in the document
+func (v *validator) lookup(p fieldPath, key bool) *yaml.Node {
+	n := v.root
+	for i, elem := range p {
+		n = resolve(n)
+		var next, keyNode *yaml.Node
+		switch elem := elem.(type) {
+		case string:
+			if n.Kind == yaml.MappingNode {
+				for j := 0; j+1 < len(n.Content); j += 2 {
+					if n.Content[j].Value == elem {
+						keyNode, next = n.Content[j], n.Content[j+1]
+					}
+				}
+			}
+		case int:
+			if n.Kind == yaml.SequenceNode && elem < len(n.Content) {
+				next = n.Content[elem]
+			}
+		}
+		if next == nil {
+			return n
+		}
+		if key && keyNode != nil && i == len(p)-1 {
+			return keyNode
+		}
+		n = next
+	}
+	return n
+}
+
+// valid returns a copy of n without the mapping entries that violate the
+// schema, or nil if n itself does. A list with an invalid item is left out
+// as a whole: removing the item would shift the indexes errors refer to.
+func (v *validator) valid(n *yaml.Node) *yaml.Node {
+	n = resolve(n)
+	if v.invalid[n] {
+		return nil
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		valid := *n
+		valid.Content = nil
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			if value := v.valid(n.Content[i+1]); value != nil && !v.invalid[key] {
+				valid.Content = append(valid.Content, key, value)
+			}
+		}
+		return &valid
+	case yaml.SequenceNode:
+		if !v.complete(n) {
+			return nil
+		}
+	}
+	return n
+}
+
+// complete reports whether n and all nodes below it match the schema
+func (v *validator) complete(n *yaml.Node) bool {
+	n = resolve(n)
+	if v.invalid[n] {
+		return false
+	}
+	for _, child := range n.Content {
+		if !v.complete(child) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(v.errs, func(a, b *Error) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	errs := make([]error, len(v.errs))
+	for i, err := range v.errs {
+		errs[i] = err
+	}
+	return errors.Join(errs...)
+}
+
+var durationType = reflect.TypeFor[metav1.Duration]()
+
+// check verifies that node n has the shape of type t: known fields, and
+// mappings, sequences and scalars where expected
+func (v *validator) check(n *yaml.Node, t reflect.Type, p fieldPath) {
+	n = resolve(n)
+	if n.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!str" {
+			v.at(n, p, "expected a duration such as 1h30m, got %s", describe(n))
+		} else if d, err := time.ParseDuration(n.Value); err != nil {
+			v.at(n, p, "invalid duration %q, expected e.g. 1h30m", n.Value)
+		} else if d < 0 {
+			v.at(n, p, "must not be negative")
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if !v.expect(n, yaml.MappingNode, p) {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Tag == "!!merge" {
+				v.check(value, t, p)
+				continue
+			}
+			field, ok := jsonField(t, key.Value)
+			if !ok {
+				v.at(key, p.key(key.Value), "unknown field, expected one of %s", strings.Join(jsonFields(t), ", "))
+				continue
+			}
+			v.check(value, field.Type, p.key(key.Value))
+		}
+	case reflect.Map:
+		if !v.expect(n, yaml.MappingNode, p) {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v.check(n.Content[i+1], t.Elem(), p.key(n.Content[i].Value))
+		}
+	case reflect.Slice:
+		if !v.expect(n, yaml.SequenceNode, p) {
+			return
+		}
+		for i, item := range n.Content {
+			v.check(item, t.Elem(), p.index(i))
+		}
+	case reflect.String:
+		if n.Kind == yaml.ScalarNode && n.Tag != "!!str" {
+			v.at(n, p, "expected a string, got %s; quote it to use it as a string", describe(n))
+		} else {
+			v.expect(n, yaml.ScalarNode, p)
+		}
+	case reflect.Int:
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!int" {
+			v.at(n, p, "expected an integer, got %s", describe(n))
+		}
+	case reflect.Bool:
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!bool" {
+			v.at(n, p, "expected a boolean, got %s", describe(n))
+		}
+	}
+}
+
+// expect records a violation unless n is of kind, and reports whether it is
+func (v *validator) expect(n *yaml.Node, kind yaml.Kind, p fieldPath) bool {
+	if n.Kind == kind {
+		return true
+	}
+	expected := map[yaml.Kind]string{
+		yaml.MappingNode:  "a mapping",
+		yaml.SequenceNode: "a list",
+		yaml.ScalarNode:   "a string",
+	}[kind]
+	v.at(n, p, "expected %s, got %s", expected, describe(n))
+	return false
+}
+
+// resolve follows aliases to the node they refer to
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// describe names the kind of value of n for error messages
+func describe(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch n.Tag {
+	case "!!int":
+		return "an integer"
+	case "!!float":
+		return "a number"
+	case "!!bool":
+		return "a boolean"
+	case "!!timestamp":
+		return "a timestamp"
+	}
+	return "a string"
+}
+
+// jsonField returns the field of struct type t named name in JSON
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for field := range t.Fields() {
+		if jsonName(field) == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonFields returns the JSON names of the fields of struct type t
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for field := range t.Fields() {
+		if name := jsonName(field); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,77 @@
+package context
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// toolConfig returns the tool's configuration of the flags, loading it once
+// when they don't provide it
+func (m *Manager) toolConfig() (*config.Config, error) {
+	if m.tool == nil {
+		c, err := m.flags.ToolConfig()
+		if err != nil {
+			return nil, err
+		}
+		m.tool = c
+	}
+	return m.tool, nil
+}
+
+// checkProtected returns an error for contexts protected by the tool config
+func (m *Manager) checkProtected(name, action string) error {
+	protected, err := m.IsProtected(name)
+	if err != nil {
+		return err
+	}
+	if protected {
+		return fmt.Errorf("context %q is protected by the config file, it can't be %s", name, action)
+	}
+	return nil
+}
+
+// IsProtected reports whether a context matches a protected pattern of the
+// tool config
+func (m *Manager) IsProtected(name string) (bool, error) {
+	c, err := m.toolConfig()
+	if err != nil {
+		return false, err
+	}
+	return c.IsProtected(name), nil
+}
+
+// Color returns the ANSI sequence of the first color rule of the tool config
+// matching a context, or "" if none does
+func (m *Manager) Color(name string) (string, error) {
+	c, err := m.toolConfig()
+	if err != nil {
+		return "", err
+	}
+
+	for _, rule := range c.Colors {
+		if rule.Context != "" {
+			if matched, _ := path.Match(rule.Context, name); !matched {
+				continue
+			}
+		}
+		if rule.Selector != "" {
+			// Validated when the config was loaded
+			selector, err := labels.Parse(rule.Selector)
+			if err != nil {
+				return "", err
+			}
+			tags, err := m.Tags(name)
+			if err != nil {
+				return "", err
+			}
+			if !selector.Matches(tags) {
+				continue
+			}
+		}
+		return config.Colors[rule.Color], nil
+	}
+	return "", nil
+}
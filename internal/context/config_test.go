@@ -0,0 +1,47 @@
+package context
+
+import (
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+)
+
+func TestColor(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	createTestKubeconfig(t, []string{"prod-eu", "prod-us", "staging", "dev"}, "dev")
+
+	testutil.WriteConfig(t, &config.Config{
+		Tags: map[string]map[string]string{"staging": {"env": "staging"}},
+		Colors: []config.ColorRule{
+			{Context: "prod-us", Color: "magenta"},
+			{Context: "prod-*", Color: "red"},
+			{Selector: "env=staging", Color: "yellow"},
+		},
+	})
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	tests := []struct {
+		context string
+		want    string
+	}{
+		{context: "prod-eu", want: config.Colors["red"]},
+		{context: "prod-us", want: config.Colors["magenta"]},
+		{context: "staging", want: config.Colors["yellow"]},
+		{context: "dev", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := manager.Color(tt.context)
+		if err != nil {
+			t.Fatalf("Color(%q) failed: %v", tt.context, err)
+		}
+		if got != tt.want {
+			t.Errorf("Color(%q) = %q, want %q", tt.context, got, tt.want)
+		}
+	}
+}
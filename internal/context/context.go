@@ -1,9 +1,18 @@
 package context
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"sort"
+	"strings"
 
+	"github.com/camaeel/kubectl-ctx/internal/clusterinfo"
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/credentials"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/namespace"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
@@ -12,31 +21,102 @@ import (
 type Manager struct {
 	config       *api.Config
 	loadingRules *clientcmd.ClientConfigLoadingRules
+	flags        *kubeconfig.ConfigFlags
+	sources      *kubeconfig.Sources
+	// tool is the tool's configuration, loaded on first use
+	tool *config.Config
 }
 
-// NewManager creates a new context manager
-func NewManager() (*Manager, error) {
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+// NewManager creates a new context manager for the kubeconfig selected by flags
+func NewManager(flags *kubeconfig.ConfigFlags) (*Manager, error) {
+	loadingRules, err := flags.LoadingRules()
+	if err != nil {
+		return nil, err
+	}
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, flags.Overrides)
 
 	rawConfig, err := kubeConfig.RawConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
+	if name := flags.Overrides.CurrentContext; name != "" {
+		if _, exists := rawConfig.Contexts[name]; !exists {
+			return nil, fmt.Errorf("context %q not found", name)
+		}
+	}
+
+	slog.Debug("Loaded kubeconfig", "files", loadingRules.GetLoadingPrecedence(), "contexts", len(rawConfig.Contexts))
+
 	return &Manager{
 		config:       &rawConfig,
 		loadingRules: loadingRules,
+		flags:        flags,
 	}, nil
 }
 
-// GetCurrentContext returns the current context name
+// GetCurrentContext returns the current context name, which --context
+// overrides like in kubectl
 func (m *Manager) GetCurrentContext() string {
+	if name := m.flags.Overrides.CurrentContext; name != "" {
+		return name
+	}
 	return m.config.CurrentContext
 }
 
+// ConfiguredContext returns the current context of the kubeconfig, ignoring
+// --context, which is what a switch replaces
+func (m *Manager) ConfiguredContext() string {
+	return m.config.CurrentContext
+}
+
+// CurrentContextFile returns the kubeconfig file that current-context is written to
+func (m *Manager) CurrentContextFile() string {
+	return m.loadingRules.GetDefaultFilename()
+}
+
+// ContextFile returns the kubeconfig file that defines a context,
+// which is where its namespace is written to
+func (m *Manager) ContextFile(name string) string {
+	ctx, exists := m.config.Contexts[name]
+	if !exists {
+		return ""
+	}
+	return ctx.LocationOfOrigin
+}
+
+// GetContext returns the merged definition of a context
+func (m *Manager) GetContext(name string) (*api.Context, error) {
+	if err := m.ValidateContext(name); err != nil {
+		return nil, err
+	}
+	return m.config.Contexts[name], nil
+}
+
+// Sources reads every kubeconfig file separately to explain where each
+// context, cluster and user comes from. The result is cached.
+func (m *Manager) Sources() (*kubeconfig.Sources, error) {
+	if m.sources == nil {
+		sources, err := kubeconfig.LoadSources(m.loadingRules)
+		if err != nil {
+			return nil, err
+		}
+		m.sources = sources
+	}
+	return m.sources, nil
+}
+
+// reload re-reads the merged kubeconfig after a write to a single file
+func (m *Manager) reload() error {
+	config, err := m.loadingRules.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	m.config = config
+	m.sources = nil
+	return nil
+}
+
 // ListContexts returns a sorted list of all available contexts
 func (m *Manager) ListContexts() []string {
 	contexts := make([]string, 0, len(m.config.Contexts))
@@ -65,11 +145,222 @@ func (m *Manager) SwitchContext(targetContext string) error {
 		return nil // Already on target context
 	}
 
-	m.config.CurrentContext = targetContext
+	slog.Debug("Writing current-context", "context", targetContext, "file", m.CurrentContextFile())
+	config, err := kubeconfig.Update(m.loadingRules, m.flags.Write, func(config *api.Config) error {
+		if _, exists := config.Contexts[targetContext]; !exists {
+			return fmt.Errorf("context %q not found", targetContext)
+		}
+		config.CurrentContext = targetContext
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to switch context: %w", err)
+	}
+
+	m.config = config
+	return nil
+}
+
+// RenameContext renames a context in the file that defines it, moving
+// current-context along if it points at the context. Protected contexts
+// can't be renamed.
+func (m *Manager) RenameContext(oldName, newName string) error {
+	if err := m.ValidateContext(oldName); err != nil {
+		return err
+	}
+	if err := m.checkProtected(oldName, "renamed"); err != nil {
+		return err
+	}
+	if newName == "" {
+		return fmt.Errorf("context name must not be empty")
+	}
+	if _, exists := m.config.Contexts[newName]; exists {
+		return fmt.Errorf("context %q already exists", newName)
+	}
+
+	slog.Debug("Renaming context", "context", oldName, "name", newName, "file", m.ContextFile(oldName))
+	config, err := kubeconfig.Update(m.loadingRules, m.flags.Write, func(config *api.Config) error {
+		ctx, exists := config.Contexts[oldName]
+		if !exists {
+			return fmt.Errorf("context %q not found", oldName)
+		}
+		if _, exists := config.Contexts[newName]; exists {
+			return fmt.Errorf("context %q already exists", newName)
+		}
+		// The copy keeps LocationOfOrigin, so it is written to the same file
+		config.Contexts[newName] = ctx.DeepCopy()
+		delete(config.Contexts, oldName)
+		if config.CurrentContext == oldName {
+			config.CurrentContext = newName
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename context: %w", err)
+	}
+
+	m.config = config
+	m.sources = nil
+	return nil
+}
+
+// DeleteContext removes a context from the file that defines it. Its
+// cluster and user are kept, as other contexts may use them. The current
+// context and protected contexts can't be deleted.
+func (m *Manager) DeleteContext(name string) error {
+	if err := m.ValidateContext(name); err != nil {
+		return err
+	}
+	if name == m.config.CurrentContext {
+		return fmt.Errorf("context %q is the current context, switch to another one first", name)
+	}
+	if err := m.checkProtected(name, "deleted"); err != nil {
+		return err
+	}
+
+	slog.Debug("Deleting context", "context", name, "file", m.ContextFile(name))
+	config, err := kubeconfig.Update(m.loadingRules, m.flags.Write, func(config *api.Config) error {
+		if _, exists := config.Contexts[name]; !exists {
+			return fmt.Errorf("context %q not found", name)
+		}
+		delete(config.Contexts, name)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete context: %w", err)
+	}
+
+	m.config = config
+	m.sources = nil
+	return nil
+}
+
+// GetContextNamespace returns the namespace configured for a context,
+// falling back to the default namespace
+func (m *Manager) GetContextNamespace(name string) string {
+	ctx, exists := m.config.Contexts[name]
+	if !exists || ctx.Namespace == "" {
+		return namespace.DefaultNamespace
+	}
+	return ctx.Namespace
+}
+
+// GetContextServer returns the server of the cluster a context uses
+func (m *Manager) GetContextServer(name string) string {
+	return kubeconfig.Server(m.config, name)
+}
+
+// ResolveTarget splits a [FILE:]CONTEXT[/NAMESPACE] argument into its parts.
+// Context names containing a slash or colon (e.g. EKS ARNs) take precedence
+// over the combined syntax, so they can still be addressed directly. Aliases
+// of the tool config expand to their target, unless a context has the name.
+func (m *Manager) ResolveTarget(target string) (string, string, error) {
+	if _, exists := m.config.Contexts[target]; exists {
+		return target, "", nil
+	}
+
+	c, err := m.toolConfig()
+	if err != nil {
+		return "", "", err
+	}
+	if alias, ok := c.Aliases[target]; ok {
+		slog.Debug("Resolved alias", "alias", target, "target", alias)
+		return m.resolveTarget(alias)
+	}
+	return m.resolveTarget(target)
+}
+
+// resolveTarget resolves a target that isn't an alias
+func (m *Manager) resolveTarget(target string) (string, string, error) {
+	if _, exists := m.config.Contexts[target]; exists {
+		return target, "", nil
+	}
+
+	if ref, ok, err := m.ParseFileRef(target); err != nil || ok {
+		if err != nil {
+			return "", "", err
+		}
+		return m.resolveFileTarget(ref)
+	}
+
+	if i := strings.LastIndex(target, "/"); i > 0 {
+		contextName, ns := target[:i], target[i+1:]
+		if _, exists := m.config.Contexts[contextName]; exists {
+			if ns == "" {
+				return "", "", fmt.Errorf("empty namespace in %q", target)
+			}
+			return contextName, ns, nil
+		}
+	}
+
+	return "", "", m.ValidateContext(target)
+}
+
+// ListNamespaces fetches namespaces from the cluster of the given context
+func (m *Manager) ListNamespaces(name string) ([]string, error) {
+	if err := m.ValidateContext(name); err != nil {
+		return nil, err
+	}
+	return namespace.ListNamespacesForContext(*m.config, name, m.flags.ContextOverrides())
+}
+
+// GetNamespaces fetches the namespace objects from the cluster of the given
+// context
+func (m *Manager) GetNamespaces(name string) ([]corev1.Namespace, error) {
+	if err := m.ValidateContext(name); err != nil {
+		return nil, err
+	}
+	return namespace.GetNamespacesForContext(*m.config, name, m.flags.ContextOverrides())
+}
+
+// Login runs the credential plugin of a context to obtain fresh credentials
+func (m *Manager) Login(name string) (*credentials.LoginResult, error) {
+	if err := m.ValidateContext(name); err != nil {
+		return nil, err
+	}
+	return credentials.Login(*m.config, name, m.flags.ContextOverrides(), m.loadingRules)
+}
+
+// CredentialExpiries returns the expiry of the credentials of a context
+func (m *Manager) CredentialExpiries(name string) []credentials.Expiry {
+	return credentials.Expiries(m.config, name)
+}
 
-	if err := clientcmd.ModifyConfig(m.loadingRules, *m.config, false); err != nil {
+// FetchClusterInfo requests the server version and node count of the
+// cluster of a context
+func (m *Manager) FetchClusterInfo(ctx context.Context, name string) clusterinfo.Info {
+	return clusterinfo.Fetch(ctx, *m.config, name, m.flags.ContextOverrides())
+}
+
+// SwitchContextAndNamespace switches to the specified context and sets its
+// namespace in a single kubeconfig write
+func (m *Manager) SwitchContextAndNamespace(targetContext, targetNamespace string) error {
+	if err := m.ValidateContext(targetContext); err != nil {
+		return err
+	}
+
+	if err := namespace.ValidateNamespace(targetNamespace); err != nil {
+		return err
+	}
+
+	if targetContext == m.config.CurrentContext && targetNamespace == m.GetContextNamespace(targetContext) {
+		return nil // Already on target context and namespace
+	}
+
+	slog.Debug("Writing current-context and namespace", "context", targetContext, "namespace", targetNamespace)
+	config, err := kubeconfig.Update(m.loadingRules, m.flags.Write, func(config *api.Config) error {
+		ctx, exists := config.Contexts[targetContext]
+		if !exists {
+			return fmt.Errorf("context %q not found", targetContext)
+		}
+		config.CurrentContext = targetContext
+		ctx.Namespace = targetNamespace
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to switch context: %w", err)
 	}
 
+	m.config = config
 	return nil
 }
@@ -5,10 +5,19 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
+// TestMain keeps the tool config of the user, read by the managers, out of
+// the tests
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunIsolated(m))
+}
+
 func createTestKubeconfig(t *testing.T, contexts []string, currentContext string) string {
 	t.Helper()
 
@@ -49,13 +58,13 @@ func createTestKubeconfig(t *testing.T, contexts []string, currentContext string
 func TestNewManager(t *testing.T) {
 	createTestKubeconfig(t, []string{"dev", "prod"}, "dev")
 
-	manager, err := NewManager()
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
 	if err != nil {
-		t.Fatalf("NewManager() failed: %v", err)
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
 	}
 
 	if manager == nil {
-		t.Fatal("NewManager() returned nil manager")
+		t.Fatal("NewManager(kubeconfig.NewConfigFlags()) returned nil manager")
 	}
 
 	if manager.config == nil {
@@ -88,9 +97,9 @@ func TestGetCurrentContext(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			createTestKubeconfig(t, tt.contexts, tt.currentContext)
 
-			manager, err := NewManager()
+			manager, err := NewManager(kubeconfig.NewConfigFlags())
 			if err != nil {
-				t.Fatalf("NewManager() failed: %v", err)
+				t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
 			}
 
 			got := manager.GetCurrentContext()
@@ -128,9 +137,9 @@ func TestListContexts(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			createTestKubeconfig(t, tt.contexts, tt.contexts[0])
 
-			manager, err := NewManager()
+			manager, err := NewManager(kubeconfig.NewConfigFlags())
 			if err != nil {
-				t.Fatalf("NewManager() failed: %v", err)
+				t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
 			}
 
 			got := manager.ListContexts()
@@ -151,9 +160,9 @@ func TestListContexts(t *testing.T) {
 func TestValidateContext(t *testing.T) {
 	createTestKubeconfig(t, []string{"dev", "staging", "prod"}, "dev")
 
-	manager, err := NewManager()
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
 	if err != nil {
-		t.Fatalf("NewManager() failed: %v", err)
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
 	}
 
 	tests := []struct {
@@ -228,9 +237,9 @@ func TestSwitchContext(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			createTestKubeconfig(t, []string{"dev", "staging", "prod"}, tt.initialCtx)
 
-			manager, err := NewManager()
+			manager, err := NewManager(kubeconfig.NewConfigFlags())
 			if err != nil {
-				t.Fatalf("NewManager() failed: %v", err)
+				t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
 			}
 
 			err = manager.SwitchContext(tt.targetCtx)
@@ -244,9 +253,9 @@ func TestSwitchContext(t *testing.T) {
 			}
 
 			// Verify the switch by creating a new manager
-			newManager, err := NewManager()
+			newManager, err := NewManager(kubeconfig.NewConfigFlags())
 			if err != nil {
-				t.Fatalf("NewManager() after switch failed: %v", err)
+				t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) after switch failed: %v", err)
 			}
 
 			if tt.expectSwitch {
@@ -296,9 +305,9 @@ func TestMultipleKubeconfigFiles(t *testing.T) {
 	// Set KUBECONFIG with multiple files
 	t.Setenv("KUBECONFIG", path1+string(os.PathListSeparator)+path2)
 
-	manager, err := NewManager()
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
 	if err != nil {
-		t.Fatalf("NewManager() with multiple configs failed: %v", err)
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) with multiple configs failed: %v", err)
 	}
 
 	// Should have both contexts
@@ -319,3 +328,184 @@ func TestMultipleKubeconfigFiles(t *testing.T) {
 		t.Errorf("CurrentContext = %v, want ctx1", manager.GetCurrentContext())
 	}
 }
+
+func TestResolveTarget(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	createTestKubeconfig(t, []string{"dev", "prod", "arn:aws:eks:eu-west-1:123:cluster/prod"}, "dev")
+
+	testutil.WriteConfig(t, &config.Config{Aliases: map[string]string{
+		"pay":  "prod/payments",
+		"eks":  "arn:aws:eks:eu-west-1:123:cluster/prod",
+		"dev":  "prod",
+		"gone": "staging",
+	}})
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		target        string
+		wantContext   string
+		wantNamespace string
+		wantErr       bool
+	}{
+		{name: "plain context", target: "prod", wantContext: "prod"},
+		{name: "context and namespace", target: "prod/payments", wantContext: "prod", wantNamespace: "payments"},
+		{name: "context containing slash", target: "arn:aws:eks:eu-west-1:123:cluster/prod", wantContext: "arn:aws:eks:eu-west-1:123:cluster/prod"},
+		{name: "slashed context and namespace", target: "arn:aws:eks:eu-west-1:123:cluster/prod/web", wantContext: "arn:aws:eks:eu-west-1:123:cluster/prod", wantNamespace: "web"},
+		{name: "empty namespace", target: "prod/", wantErr: true},
+		{name: "unknown context", target: "staging/payments", wantErr: true},
+		{name: "alias", target: "eks", wantContext: "arn:aws:eks:eu-west-1:123:cluster/prod"},
+		{name: "alias with namespace", target: "pay", wantContext: "prod", wantNamespace: "payments"},
+		{name: "context shadowing alias", target: "dev", wantContext: "dev"},
+		{name: "alias of unknown context", target: "gone", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotContext, gotNamespace, err := manager.ResolveTarget(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotContext != tt.wantContext || gotNamespace != tt.wantNamespace {
+				t.Errorf("ResolveTarget() = (%q, %q), want (%q, %q)", gotContext, gotNamespace, tt.wantContext, tt.wantNamespace)
+			}
+		})
+	}
+}
+
+func TestSwitchContextAndNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		targetCtx string
+		targetNs  string
+		wantErr   bool
+	}{
+		{name: "switch context and namespace", targetCtx: "prod", targetNs: "payments"},
+		{name: "same context new namespace", targetCtx: "dev", targetNs: "tools"},
+		{name: "invalid namespace", targetCtx: "prod", targetNs: "Payments", wantErr: true},
+		{name: "nonexistent context", targetCtx: "nonexistent", targetNs: "payments", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			createTestKubeconfig(t, []string{"dev", "prod"}, "dev")
+
+			manager, err := NewManager(kubeconfig.NewConfigFlags())
+			if err != nil {
+				t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+			}
+
+			err = manager.SwitchContextAndNamespace(tt.targetCtx, tt.targetNs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SwitchContextAndNamespace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			newManager, err := NewManager(kubeconfig.NewConfigFlags())
+			if err != nil {
+				t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) after switch failed: %v", err)
+			}
+
+			if tt.wantErr {
+				if newManager.GetCurrentContext() != "dev" {
+					t.Errorf("Context changed unexpectedly: got %v", newManager.GetCurrentContext())
+				}
+				return
+			}
+
+			if newManager.GetCurrentContext() != tt.targetCtx {
+				t.Errorf("Context not switched: got %v, want %v", newManager.GetCurrentContext(), tt.targetCtx)
+			}
+			if got := newManager.GetContextNamespace(tt.targetCtx); got != tt.targetNs {
+				t.Errorf("Namespace not set: got %v, want %v", got, tt.targetNs)
+			}
+		})
+	}
+}
+
+func TestRenameContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldName     string
+		newName     string
+		wantErr     bool
+		wantCurrent string
+	}{
+		{name: "rename current context", oldName: "dev", newName: "development", wantCurrent: "development"},
+		{name: "rename other context", oldName: "prod", newName: "production", wantCurrent: "dev"},
+		{name: "existing name", oldName: "prod", newName: "staging", wantErr: true},
+		{name: "empty name", oldName: "prod", newName: "", wantErr: true},
+		{name: "nonexistent context", oldName: "nonexistent", newName: "other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			createTestKubeconfig(t, []string{"dev", "staging", "prod"}, "dev")
+
+			manager, err := NewManager(kubeconfig.NewConfigFlags())
+			if err != nil {
+				t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+			}
+
+			err = manager.RenameContext(tt.oldName, tt.newName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenameContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			newManager, err := NewManager(kubeconfig.NewConfigFlags())
+			if err != nil {
+				t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) after rename failed: %v", err)
+			}
+			if newManager.ValidateContext(tt.oldName) == nil {
+				t.Errorf("Context %q still exists after rename", tt.oldName)
+			}
+			if err := newManager.ValidateContext(tt.newName); err != nil {
+				t.Errorf("Renamed context missing: %v", err)
+			}
+			if got := newManager.GetCurrentContext(); got != tt.wantCurrent {
+				t.Errorf("GetCurrentContext() = %v, want %v", got, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestDeleteContext(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	createTestKubeconfig(t, []string{"dev", "staging", "prod"}, "dev")
+
+	testutil.WriteConfig(t, &config.Config{Protected: []string{"stag*"}})
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	if err := manager.DeleteContext("dev"); err == nil {
+		t.Error("DeleteContext() of the current context succeeded, want error")
+	}
+	if err := manager.DeleteContext("nonexistent"); err == nil {
+		t.Error("DeleteContext() of a nonexistent context succeeded, want error")
+	}
+	if err := manager.DeleteContext("staging"); err == nil {
+		t.Error("DeleteContext() of a protected context succeeded, want error")
+	}
+	if err := manager.DeleteContext("prod"); err != nil {
+		t.Fatalf("DeleteContext() failed: %v", err)
+	}
+
+	newManager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) after delete failed: %v", err)
+	}
+	got := newManager.ListContexts()
+	want := []string{"dev", "staging"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ListContexts() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,286 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Redacted replaces secrets in descriptions
+const Redacted = "REDACTED"
+
+// SourceInline is the source of certificates embedded in the kubeconfig
+const SourceInline = "inline"
+
+// ProbeTimeout bounds the request made by Probe
+const ProbeTimeout = 5 * time.Second
+
+// Description is everything the kubeconfig says about a context
+type Description struct {
+	Context ContextDescription  `json:"context"`
+	Cluster *ClusterDescription `json:"cluster,omitempty"`
+	User    *UserDescription    `json:"user,omitempty"`
+	Probe   *ProbeResult        `json:"probe,omitempty"`
+}
+
+// ContextDescription describes the context entry
+type ContextDescription struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Current bool   `json:"current"`
+	Cluster string `json:"cluster"`
+	User    string `json:"user"`
+	// Namespace is the default namespace, "default" when not set
+	Namespace string `json:"namespace"`
+	// Tags are the tags of the context, from the kubeconfig and the config
+	// file
+	Tags       map[string]string          `json:"tags,omitempty"`
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// ClusterDescription describes the cluster a context uses
+type ClusterDescription struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Server string `json:"server"`
+	// CertificateAuthority is the CA file, SourceInline or empty for the
+	// system roots
+	CertificateAuthority  string                     `json:"certificateAuthority,omitempty"`
+	InsecureSkipTLSVerify bool                       `json:"insecureSkipTLSVerify"`
+	TLSServerName         string                     `json:"tlsServerName,omitempty"`
+	ProxyURL              string                     `json:"proxyURL,omitempty"`
+	Extensions            map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// UserDescription describes the user a context authenticates as, with
+// secrets redacted
+type UserDescription struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	// Auth lists the configured authentication methods
+	Auth              []string                   `json:"auth"`
+	ClientCertificate string                     `json:"clientCertificate,omitempty"`
+	ClientKey         string                     `json:"clientKey,omitempty"`
+	Token             string                     `json:"token,omitempty"`
+	Username          string                     `json:"username,omitempty"`
+	Exec              *ExecDescription           `json:"exec,omitempty"`
+	AuthProvider      *AuthProviderDescription   `json:"authProvider,omitempty"`
+	Impersonate       string                     `json:"impersonate,omitempty"`
+	Extensions        map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// ExecDescription describes an exec credential plugin. Only the names of
+// its environment variables are shown.
+type ExecDescription struct {
+	Command         string   `json:"command"`
+	Args            []string `json:"args,omitempty"`
+	Env             []string `json:"env,omitempty"`
+	APIVersion      string   `json:"apiVersion"`
+	InteractiveMode string   `json:"interactiveMode,omitempty"`
+}
+
+// AuthProviderDescription describes an auth-provider, with tokens and
+// secrets in its config redacted
+type AuthProviderDescription struct {
+	Name   string            `json:"name"`
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// ProbeResult is the outcome of contacting the server of a context
+type ProbeResult struct {
+	Reachable     bool   `json:"reachable"`
+	ServerVersion string `json:"serverVersion,omitempty"`
+	Latency       string `json:"latency,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Authentication methods of a user
+const (
+	AuthClientCertificate = "client-certificate"
+	AuthToken             = "token"
+	AuthBasic             = "basic"
+	AuthExec              = "exec"
+	AuthProvider          = "auth-provider"
+	AuthNone              = "none"
+)
+
+// Describe returns the merged definition of a context, its cluster and its
+// user, each with the file it comes from
+func (m *Manager) Describe(name string) (*Description, error) {
+	ctx, err := m.GetContext(name)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := m.Tags(name)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Description{
+		Context: ContextDescription{
+			Name:       name,
+			File:       ctx.LocationOfOrigin,
+			Current:    name == m.GetCurrentContext(),
+			Cluster:    ctx.Cluster,
+			User:       ctx.AuthInfo,
+			Namespace:  m.GetContextNamespace(name),
+			Tags:       tags,
+			Extensions: describeExtensions(ctx.Extensions),
+		},
+	}
+	if cluster := m.config.Clusters[ctx.Cluster]; cluster != nil {
+		d.Cluster = describeCluster(ctx.Cluster, cluster)
+	}
+	if user := m.config.AuthInfos[ctx.AuthInfo]; user != nil {
+		d.User = describeUser(ctx.AuthInfo, user)
+	}
+	return d, nil
+}
+
+// Probe requests the server version of a context to check that its server
+// is reachable with its credentials
+func (m *Manager) Probe(name string) (*ProbeResult, error) {
+	if err := m.ValidateContext(name); err != nil {
+		return nil, err
+	}
+
+	result := &ProbeResult{}
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*m.config, name, m.flags.ContextOverrides(), nil).ClientConfig()
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	restConfig.Timeout = ProbeTimeout
+
+	client, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	start := time.Now()
+	version, err := client.ServerVersion()
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Reachable = true
+	result.ServerVersion = version.GitVersion
+	result.Latency = time.Since(start).Round(time.Millisecond).String()
+	return result, nil
+}
+
+func describeCluster(name string, cluster *api.Cluster) *ClusterDescription {
+	d := &ClusterDescription{
+		Name:                  name,
+		File:                  cluster.LocationOfOrigin,
+		Server:                cluster.Server,
+		CertificateAuthority:  cluster.CertificateAuthority,
+		InsecureSkipTLSVerify: cluster.InsecureSkipTLSVerify,
+		TLSServerName:         cluster.TLSServerName,
+		ProxyURL:              cluster.ProxyURL,
+		Extensions:            describeExtensions(cluster.Extensions),
+	}
+	if len(cluster.CertificateAuthorityData) > 0 {
+		d.CertificateAuthority = SourceInline
+	}
+	return d
+}
+
+func describeUser(name string, user *api.AuthInfo) *UserDescription {
+	d := &UserDescription{
+		Name:              name,
+		File:              user.LocationOfOrigin,
+		ClientCertificate: user.ClientCertificate,
+		ClientKey:         user.ClientKey,
+		Username:          user.Username,
+		Impersonate:       user.Impersonate,
+		Extensions:        describeExtensions(user.Extensions),
+	}
+
+	if len(user.ClientCertificateData) > 0 {
+		d.ClientCertificate = SourceInline
+	}
+	if len(user.ClientKeyData) > 0 {
+		d.ClientKey = Redacted
+	}
+	if d.ClientCertificate != "" {
+		d.Auth = append(d.Auth, AuthClientCertificate)
+	}
+
+	switch {
+	case user.Token != "":
+		d.Token = Redacted
+		d.Auth = append(d.Auth, AuthToken)
+	case user.TokenFile != "":
+		d.Token = user.TokenFile
+		d.Auth = append(d.Auth, AuthToken)
+	}
+
+	if user.Username != "" || user.Password != "" {
+		d.Auth = append(d.Auth, AuthBasic)
+	}
+
+	if user.Exec != nil {
+		d.Exec = &ExecDescription{
+			Command:         user.Exec.Command,
+			Args:            user.Exec.Args,
+			APIVersion:      user.Exec.APIVersion,
+			InteractiveMode: string(user.Exec.InteractiveMode),
+		}
+		for _, env := range user.Exec.Env {
+			d.Exec.Env = append(d.Exec.Env, env.Name)
+		}
+		d.Auth = append(d.Auth, AuthExec)
+	}
+
+	if user.AuthProvider != nil {
+		d.AuthProvider = &AuthProviderDescription{Name: user.AuthProvider.Name}
+		for key, value := range user.AuthProvider.Config {
+			if d.AuthProvider.Config == nil {
+				d.AuthProvider.Config = map[string]string{}
+			}
+			if isSecretKey(key) {
+				value = Redacted
+			}
+			d.AuthProvider.Config[key] = value
+		}
+		d.Auth = append(d.Auth, AuthProvider)
+	}
+
+	if len(d.Auth) == 0 {
+		d.Auth = []string{AuthNone}
+	}
+	return d
+}
+
+// isSecretKey reports whether an auth-provider config key holds a secret,
+// like id-token, refresh-token or client-secret
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "token") || strings.Contains(key, "secret") || strings.Contains(key, "password")
+}
+
+// describeExtensions renders extensions as JSON, which is how client-go
+// keeps unknown extension objects
+func describeExtensions(extensions map[string]runtime.Object) map[string]json.RawMessage {
+	if len(extensions) == 0 {
+		return nil
+	}
+
+	rendered := make(map[string]json.RawMessage, len(extensions))
+	for name, extension := range extensions {
+		raw, err := json.Marshal(extension)
+		if err != nil {
+			raw, _ = json.Marshal(fmt.Sprintf("<%v>", err))
+		}
+		rendered[name] = raw
+	}
+	return rendered
+}
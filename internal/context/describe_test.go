@@ -0,0 +1,162 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+)
+
+const describeKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+    certificate-authority: ca.crt
+    tls-server-name: api.prod
+    proxy-url: socks5://localhost:1080
+    extensions:
+    - name: client.authentication.k8s.io/exec
+      extension:
+        audience: prod
+- name: dev
+  cluster:
+    server: %SERVER%
+    insecure-skip-tls-verify: true
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: oidc
+    namespace: payments
+- name: dev
+  context:
+    cluster: dev
+    user: exec
+- name: cert
+  context:
+    cluster: dev
+    user: cert
+users:
+- name: oidc
+  user:
+    auth-provider:
+      name: oidc
+      config:
+        client-id: kubernetes
+        client-secret: s3cr3t
+        id-token: header.payload.signature
+        idp-issuer-url: https://issuer.example.com
+- name: exec
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: aws
+      args: [eks, get-token]
+      env:
+      - name: AWS_PROFILE
+        value: prod
+- name: cert
+  user:
+    client-certificate-data: Y2VydA==
+    client-key-data: a2V5
+    token: s3cr3t
+`
+
+func createDescribeKubeconfig(t *testing.T, server string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config")
+	content := strings.Replace(describeKubeconfig, "%SERVER%", server, 1)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	t.Setenv("KUBECONFIG", path)
+	return path
+}
+
+func TestDescribe(t *testing.T) {
+	path := createDescribeKubeconfig(t, "https://dev.example.com")
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	d, err := manager.Describe("prod")
+	if err != nil {
+		t.Fatalf("Describe(prod) failed: %v", err)
+	}
+	if !d.Context.Current || d.Context.File != path || d.Context.Namespace != "payments" {
+		t.Errorf("Unexpected context %+v", d.Context)
+	}
+	if c := d.Cluster; c.Server != "https://prod.example.com:6443" || c.CertificateAuthority != filepath.Join(filepath.Dir(path), "ca.crt") ||
+		c.TLSServerName != "api.prod" || c.ProxyURL != "socks5://localhost:1080" || c.File != path {
+		t.Errorf("Unexpected cluster %+v", c)
+	}
+	if ext := string(d.Cluster.Extensions["client.authentication.k8s.io/exec"]); ext != `{"audience":"prod"}` {
+		t.Errorf("Unexpected extension %s", ext)
+	}
+	if p := d.User.AuthProvider; p == nil || p.Name != "oidc" || p.Config["client-id"] != "kubernetes" ||
+		p.Config["client-secret"] != Redacted || p.Config["id-token"] != Redacted {
+		t.Errorf("Unexpected auth provider %+v", p)
+	}
+
+	d, err = manager.Describe("dev")
+	if err != nil {
+		t.Fatalf("Describe(dev) failed: %v", err)
+	}
+	if d.Context.Current || d.Context.Namespace != "default" || !d.Cluster.InsecureSkipTLSVerify {
+		t.Errorf("Unexpected description %+v %+v", d.Context, d.Cluster)
+	}
+	if e := d.User.Exec; e == nil || e.Command != "aws" || strings.Join(e.Env, ",") != "AWS_PROFILE" {
+		t.Errorf("Unexpected exec %+v", e)
+	}
+
+	d, err = manager.Describe("cert")
+	if err != nil {
+		t.Fatalf("Describe(cert) failed: %v", err)
+	}
+	u := d.User
+	if strings.Join(u.Auth, ",") != AuthClientCertificate+","+AuthToken {
+		t.Errorf("Unexpected auth %v", u.Auth)
+	}
+	if u.ClientCertificate != SourceInline || u.ClientKey != Redacted || u.Token != Redacted {
+		t.Errorf("Secrets not redacted: %+v", u)
+	}
+
+	if _, err := manager.Describe("unknown"); err == nil {
+		t.Error("Expected error for unknown context")
+	}
+}
+
+func TestProbe(t *testing.T) {
+	createDescribeKubeconfig(t, testutil.NewFakeAPIServer(t, nil))
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	// The cert context has no exec plugin to run
+	result, err := manager.Probe("cert")
+	if err != nil {
+		t.Fatalf("Probe(cert) failed: %v", err)
+	}
+	if !result.Reachable || result.ServerVersion != "v1.36.3" {
+		t.Errorf("Unexpected probe result %+v", result)
+	}
+
+	result, err = manager.Probe("prod")
+	if err != nil {
+		t.Fatalf("Probe(prod) failed: %v", err)
+	}
+	if result.Reachable || result.Error == "" {
+		t.Errorf("Expected unreachable server, got %+v", result)
+	}
+}
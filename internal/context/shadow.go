@@ -0,0 +1,334 @@
+package context
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/utils/paths"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// FileRef addresses a name as defined in one specific kubeconfig file
+type FileRef struct {
+	File string
+	Name string
+}
+
+// ShadowedContexts returns the context definitions hidden by a context of
+// the same name in an earlier KUBECONFIG file
+func (m *Manager) ShadowedContexts() ([]kubeconfig.Definition, error) {
+	sources, err := m.Sources()
+	if err != nil {
+		return nil, err
+	}
+
+	var shadowed []kubeconfig.Definition
+	for _, d := range sources.Shadowed() {
+		if d.Kind == kubeconfig.KindContext {
+			shadowed = append(shadowed, d)
+		}
+	}
+	return shadowed, nil
+}
+
+// WarnShadowed logs a warning for each shadowed context. The warning is
+// shown once per shell session until the set of shadowed contexts changes.
+func (m *Manager) WarnShadowed() {
+	shadowed, err := m.ShadowedContexts()
+	if err != nil {
+		slog.Debug("Failed to detect shadowed contexts", "error", err)
+		return
+	}
+	if len(shadowed) == 0 {
+		return
+	}
+
+	var key strings.Builder
+	for _, d := range shadowed {
+		fmt.Fprintf(&key, "%s\t%s\t%s\n", d.Name, d.File, d.ShadowedBy)
+	}
+	if !firstInSession("shadowed", []byte(key.String())) {
+		return
+	}
+
+	for _, d := range shadowed {
+		slog.Warn("Context is shadowed by an earlier kubeconfig file and unreachable by name",
+			"context", d.Name, "file", d.File, "shadowedBy", d.ShadowedBy)
+	}
+}
+
+// sessionExpiry is how long session records are kept, as ended sessions
+// can't be told apart from running ones portably
+const sessionExpiry = 24 * time.Hour
+
+// firstInSession records content under name for the current session and
+// reports whether it differs from what was recorded before. Failing to
+// record is treated as a first occurrence. Records of other sessions older
+// than sessionExpiry are removed.
+func firstInSession(name string, content []byte) bool {
+	dir, err := paths.StateDir()
+	if err != nil {
+		return true
+	}
+	dir = filepath.Join(dir, "sessions")
+	file := filepath.Join(dir, fmt.Sprintf("%s-%d", name, sessionID()))
+	pruneSessions(dir)
+
+	if previous, err := os.ReadFile(file); err == nil && bytes.Equal(previous, content) {
+		return false
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		slog.Debug("Failed to create session state directory", "error", err)
+		return true
+	}
+	if err := os.WriteFile(file, content, 0600); err != nil {
+		slog.Debug("Failed to record session state", "error", err)
+	}
+	return true
+}
+
+// pruneSessions removes the session records not written for sessionExpiry
+func pruneSessions(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < sessionExpiry {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			slog.Debug("Failed to remove session record", "file", entry.Name(), "error", err)
+		}
+	}
+}
+
+// sessionID identifies the shell session by the PID of the shell: the
+// parent process, or kubectl's parent when run as a kubectl plugin, since
+// kubectl is a new process on every call
+func sessionID() int {
+	pid := os.Getppid()
+	if name, ppid, err := parentProcess(pid); err == nil && ppid > 1 &&
+		strings.TrimSuffix(filepath.Base(name), ".exe") == "kubectl" {
+		return ppid
+	}
+	return pid
+}
+
+// parentProcess returns the command name and parent PID of a process, from
+// /proc where available and ps otherwise
+func parentProcess(pid int) (string, int, error) {
+	if stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		// pid (comm) state ppid ..., where comm may contain spaces
+		start, end := bytes.IndexByte(stat, '('), bytes.LastIndexByte(stat, ')')
+		fields := strings.Fields(string(stat[end+1:]))
+		if start < 0 || end < start || len(fields) < 2 {
+			return "", 0, fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		return string(stat[start+1 : end]), ppid, err
+	}
+
+	out, err := exec.Command("ps", "-o", "ppid=", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", 0, err
+	}
+	ppid, name, found := strings.Cut(strings.TrimSpace(string(out)), " ")
+	if !found {
+		return "", 0, fmt.Errorf("unexpected ps output %q", out)
+	}
+	id, err := strconv.Atoi(ppid)
+	return strings.TrimSpace(name), id, err
+}
+
+// ParseFileRef parses a FILE:NAME reference, where FILE is a path from the
+// loading precedence or its base name (e.g. file2.yaml:shared-context).
+// ok is false when target does not start with a known file.
+func (m *Manager) ParseFileRef(target string) (ref FileRef, ok bool, err error) {
+	sources, err := m.Sources()
+	if err != nil {
+		return FileRef{}, false, err
+	}
+
+	var matches []FileRef
+	for _, f := range sources.Files {
+		if !f.Exists {
+			continue
+		}
+		for _, prefix := range []string{f.Path, filepath.Base(f.Path)} {
+			if name, found := strings.CutPrefix(target, prefix+":"); found && name != "" {
+				matches = append(matches, FileRef{File: f.Path, Name: name})
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return FileRef{}, false, nil
+	case 1:
+		return matches[0], true, nil
+	default:
+		return FileRef{}, false, fmt.Errorf("%q matches several kubeconfig files (%s and %s); use the full path",
+			target, matches[0].File, matches[1].File)
+	}
+}
+
+// resolveFileTarget resolves a FILE:CONTEXT[/NAMESPACE] reference. Since
+// kubectl selects contexts by name, a shadowed definition can't be switched
+// to; the error suggests renaming it to NAME@FILE, without extension.
+func (m *Manager) resolveFileTarget(ref FileRef) (string, string, error) {
+	sources, err := m.Sources()
+	if err != nil {
+		return "", "", err
+	}
+
+	contexts := sources.Config(ref.File).Contexts
+	name, ns := ref.Name, ""
+	if _, exists := contexts[name]; !exists {
+		if i := strings.LastIndex(name, "/"); i > 0 {
+			name, ns = name[:i], name[i+1:]
+		}
+		if _, exists := contexts[name]; !exists {
+			return "", "", fmt.Errorf("context %q not found in %s", ref.Name, ref.File)
+		}
+		if ns == "" {
+			return "", "", fmt.Errorf("empty namespace in %q", ref.Name)
+		}
+	}
+
+	if defs := sources.Lookup(kubeconfig.KindContext, name); defs[0].File != ref.File {
+		newName := name + "@" + strings.TrimSuffix(filepath.Base(ref.File), filepath.Ext(ref.File))
+		return "", "", fmt.Errorf("context %q in %s is shadowed by %s and kubectl selects contexts by name; "+
+			"rename it first with \"rename %s:%s %s\"", name, ref.File, defs[0].File, ref.File, name, newName)
+	}
+
+	return name, ns, nil
+}
+
+// RenameFileContext renames the definition of a context in a specific file.
+// A shadowed definition is renamed in its file, leaving the one shadowing it
+// alone. Its cluster and user, when shadowed as well, are copied under the
+// new name, so the renamed context keeps using those of its file.
+func (m *Manager) RenameFileContext(ref FileRef, newName string) error {
+	sources, err := m.Sources()
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(sources.Lookup(kubeconfig.KindContext, ref.Name), func(d kubeconfig.Definition) bool {
+		return d.File == ref.File
+	}) {
+		return fmt.Errorf("context %q not found in %s", ref.Name, ref.File)
+	}
+	if !isShadowed(sources, kubeconfig.KindContext, ref.Name, ref.File) {
+		return m.RenameContext(ref.Name, newName)
+	}
+
+	if err := m.checkProtected(ref.Name, "renamed"); err != nil {
+		return err
+	}
+	if newName == "" {
+		return fmt.Errorf("context name must not be empty")
+	}
+	if _, exists := m.config.Contexts[newName]; exists {
+		return fmt.Errorf("context %q already exists", newName)
+	}
+
+	slog.Debug("Renaming shadowed context", "context", ref.Name, "name", newName, "file", ref.File)
+	_, err = kubeconfig.Update(&clientcmd.ClientConfigLoadingRules{ExplicitPath: ref.File}, m.flags.Write, func(config *api.Config) error {
+		ctx, exists := config.Contexts[ref.Name]
+		if !exists {
+			return fmt.Errorf("context %q not found in %s", ref.Name, ref.File)
+		}
+		renamed := ctx.DeepCopy()
+
+		if isShadowed(sources, kubeconfig.KindCluster, ctx.Cluster, ref.File) {
+			if _, exists := m.config.Clusters[newName]; exists {
+				return fmt.Errorf("cluster %q already exists", newName)
+			}
+			config.Clusters[newName] = config.Clusters[ctx.Cluster].DeepCopy()
+			renamed.Cluster = newName
+		}
+		if isShadowed(sources, kubeconfig.KindUser, ctx.AuthInfo, ref.File) {
+			if _, exists := m.config.AuthInfos[newName]; exists {
+				return fmt.Errorf("user %q already exists", newName)
+			}
+			config.AuthInfos[newName] = config.AuthInfos[ctx.AuthInfo].DeepCopy()
+			renamed.AuthInfo = newName
+		}
+
+		config.Contexts[newName] = renamed
+		delete(config.Contexts, ref.Name)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename context: %w", err)
+	}
+
+	return m.reload()
+}
+
+// isShadowed reports whether the definition of a name in file is hidden by
+// an earlier file
+func isShadowed(sources *kubeconfig.Sources, kind, name, file string) bool {
+	return slices.ContainsFunc(sources.Lookup(kind, name), func(d kubeconfig.Definition) bool {
+		return d.File == file && d.ShadowedBy != ""
+	})
+}
+
+// Export returns a self-contained kubeconfig with only a [FILE:]CONTEXT, its
+// cluster and its user, like "kubectl config view --minify --flatten". With
+// FILE, the definitions of that file are exported, even when shadowed.
+func (m *Manager) Export(target string) (*api.Config, error) {
+	config := m.config.DeepCopy()
+	name := target
+
+	if _, exists := config.Contexts[target]; !exists {
+		ref, ok, err := m.ParseFileRef(target)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, m.ValidateContext(target)
+		}
+		sources, err := m.Sources()
+		if err != nil {
+			return nil, err
+		}
+
+		file := sources.Config(ref.File)
+		ctx, exists := file.Contexts[ref.Name]
+		if !exists {
+			return nil, fmt.Errorf("context %q not found in %s", ref.Name, ref.File)
+		}
+		name = ref.Name
+		config.Contexts[name] = ctx.DeepCopy()
+		// Names the file doesn't define resolve through the merged view
+		if cluster, exists := file.Clusters[ctx.Cluster]; exists {
+			config.Clusters[ctx.Cluster] = cluster.DeepCopy()
+		}
+		if user, exists := file.AuthInfos[ctx.AuthInfo]; exists {
+			config.AuthInfos[ctx.AuthInfo] = user.DeepCopy()
+		}
+	}
+
+	config.CurrentContext = name
+	if err := api.MinifyConfig(config); err != nil {
+		return nil, err
+	}
+	if err := api.FlattenConfig(config); err != nil {
+		return nil, fmt.Errorf("failed to embed the files of context %q: %w", name, err)
+	}
+	return config, nil
+}
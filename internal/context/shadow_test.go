@@ -0,0 +1,216 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/utils/paths"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// createShadowedKubeconfigs writes file1.yaml and file2.yaml, both defining
+// shared-context, and returns their paths
+func createShadowedKubeconfigs(t *testing.T) (string, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	write := func(name string, contexts ...string) string {
+		config := api.NewConfig()
+		for _, ctx := range contexts {
+			config.Contexts[ctx] = &api.Context{Cluster: name, AuthInfo: name}
+		}
+		config.Clusters[name] = &api.Cluster{Server: "https://" + name + ":6443"}
+		config.AuthInfos[name] = &api.AuthInfo{Token: "token"}
+		config.CurrentContext = contexts[0]
+
+		path := filepath.Join(tmpDir, name+".yaml")
+		if err := clientcmd.WriteToFile(*config, path); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	path1 := write("file1", "ctx1", "shared-context")
+	path2 := write("file2", "ctx2", "shared-context")
+	t.Setenv("KUBECONFIG", path1+string(os.PathListSeparator)+path2)
+	return path1, path2
+}
+
+func TestShadowedContexts(t *testing.T) {
+	path1, path2 := createShadowedKubeconfigs(t)
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	shadowed, err := manager.ShadowedContexts()
+	if err != nil {
+		t.Fatalf("ShadowedContexts() failed: %v", err)
+	}
+	if len(shadowed) != 1 {
+		t.Fatalf("Expected 1 shadowed context, got %v", shadowed)
+	}
+	if d := shadowed[0]; d.Name != "shared-context" || d.File != path2 || d.ShadowedBy != path1 {
+		t.Errorf("Unexpected shadowed definition %+v", d)
+	}
+}
+
+func TestResolveTarget_FileRef(t *testing.T) {
+	path1, _ := createShadowedKubeconfigs(t)
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		target        string
+		wantContext   string
+		wantNamespace string
+		wantErr       string
+	}{
+		{name: "base name", target: "file2.yaml:ctx2", wantContext: "ctx2"},
+		{name: "full path", target: path1 + ":shared-context", wantContext: "shared-context"},
+		{name: "with namespace", target: "file1.yaml:shared-context/web", wantContext: "shared-context", wantNamespace: "web"},
+		{name: "shadowed", target: "file2.yaml:shared-context/web", wantErr: "shared-context@file2"},
+		{name: "not in file", target: "file1.yaml:ctx2", wantErr: "not found in"},
+		{name: "empty namespace", target: "file1.yaml:ctx1/", wantErr: "empty namespace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotContext, gotNamespace, err := manager.ResolveTarget(tt.target)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveTarget() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveTarget() failed: %v", err)
+			}
+			if gotContext != tt.wantContext || gotNamespace != tt.wantNamespace {
+				t.Errorf("ResolveTarget() = (%q, %q), want (%q, %q)", gotContext, gotNamespace, tt.wantContext, tt.wantNamespace)
+			}
+		})
+	}
+}
+
+func TestRenameFileContext(t *testing.T) {
+	path1, path2 := createShadowedKubeconfigs(t)
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	if err := manager.RenameFileContext(FileRef{File: path2, Name: "shared-context"}, "ctx1"); err == nil {
+		t.Error("Expected renaming to an existing name to fail")
+	}
+	if err := manager.RenameFileContext(FileRef{File: path2, Name: "shared-context"}, "shared-2"); err != nil {
+		t.Fatalf("RenameFileContext() failed: %v", err)
+	}
+
+	if got := manager.ContextFile("shared-2"); got != path2 {
+		t.Errorf("Expected shared-2 in %s, got %q", path2, got)
+	}
+	if got := manager.ContextFile("shared-context"); got != path1 {
+		t.Errorf("Expected shared-context to stay in %s, got %q", path1, got)
+	}
+	shadowed, err := manager.ShadowedContexts()
+	if err != nil {
+		t.Fatalf("ShadowedContexts() failed: %v", err)
+	}
+	if len(shadowed) != 0 {
+		t.Errorf("Expected no shadowed contexts, got %v", shadowed)
+	}
+}
+
+func TestExport(t *testing.T) {
+	_, _ = createShadowedKubeconfigs(t)
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	for target, server := range map[string]string{
+		"shared-context":            "https://file1:6443",
+		"file2.yaml:shared-context": "https://file2:6443",
+	} {
+		config, err := manager.Export(target)
+		if err != nil {
+			t.Fatalf("Export(%q) failed: %v", target, err)
+		}
+		if len(config.Contexts) != 1 || config.CurrentContext != "shared-context" {
+			t.Errorf("Export(%q) contexts = %v, current = %q", target, config.Contexts, config.CurrentContext)
+		}
+		if got := kubeconfig.Server(config, "shared-context"); got != server {
+			t.Errorf("Export(%q) server = %q, want %q", target, got, server)
+		}
+	}
+
+	if _, err := manager.Export("file1.yaml:ctx2"); err == nil {
+		t.Error("Expected exporting a context missing from the file to fail")
+	}
+}
+
+func TestFirstInSession(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	if !firstInSession("test", []byte("a")) {
+		t.Error("Expected first occurrence")
+	}
+	if firstInSession("test", []byte("a")) {
+		t.Error("Expected repeated content to be suppressed")
+	}
+	if !firstInSession("test", []byte("b")) {
+		t.Error("Expected changed content to be reported")
+	}
+}
+
+func TestFirstInSession_PrunesExpired(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	dir, err := paths.StateDir()
+	if err != nil {
+		t.Fatalf("StateDir() failed: %v", err)
+	}
+	dir = filepath.Join(dir, "sessions")
+	stale := filepath.Join(dir, "test-1")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("a"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * sessionExpiry)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	firstInSession("test", []byte("a"))
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Expected expired session record to be removed, got %v", err)
+	}
+}
+
+func TestParentProcess(t *testing.T) {
+	name, ppid, err := parentProcess(os.Getpid())
+	if err != nil {
+		t.Skipf("Process information unavailable: %v", err)
+	}
+	if ppid != os.Getppid() {
+		t.Errorf("parentProcess() ppid = %d, want %d", ppid, os.Getppid())
+	}
+	if !strings.Contains(filepath.Base(os.Args[0]), name) {
+		t.Errorf("parentProcess() name = %q, want a prefix of %q", name, filepath.Base(os.Args[0]))
+	}
+}
@@ -0,0 +1,46 @@
+package context
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/usage"
+)
+
+// SortContexts orders context names in place, in one of config.SortOrders.
+// Contexts that compare equal, e.g. ones never switched to, are sorted by
+// name.
+func (m *Manager) SortContexts(contexts []string, order string, u usage.Usage) error {
+	if err := config.ValidateSortOrder(order); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var compare func(a, b string) int
+	switch order {
+	case config.SortFrecency:
+		compare = func(a, b string) int { return cmp.Compare(u[b].Frecency(now), u[a].Frecency(now)) }
+	case config.SortRecent:
+		compare = func(a, b string) int { return u[b].Last.Compare(u[a].Last) }
+	case config.SortCluster:
+		compare = func(a, b string) int { return cmp.Compare(m.contextCluster(a), m.contextCluster(b)) }
+	default:
+		compare = func(string, string) int { return 0 }
+	}
+
+	slices.SortFunc(contexts, func(a, b string) int {
+		return cmp.Or(compare(a, b), strings.Compare(a, b))
+	})
+	return nil
+}
+
+// contextCluster returns the cluster name a context uses
+func (m *Manager) contextCluster(name string) string {
+	if ctx, exists := m.config.Contexts[name]; exists {
+		return ctx.Cluster
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package context
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/usage"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestSortContexts(t *testing.T) {
+	path := createTestKubeconfig(t, []string{"a-old", "b-daily", "c-new", "d-unused"}, "a-old")
+
+	// Give the contexts different clusters
+	kc, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load test kubeconfig: %v", err)
+	}
+	kc.Clusters["z-cluster"] = &api.Cluster{Server: "https://z:6443"}
+	kc.Contexts["a-old"].Cluster = "z-cluster"
+	kc.Contexts["c-new"].Cluster = "z-cluster"
+	if err := clientcmd.WriteToFile(*kc, path); err != nil {
+		t.Fatalf("Failed to write test kubeconfig: %v", err)
+	}
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	now := time.Now()
+	u := usage.Usage{
+		"a-old":   {Count: 50, Last: now.Add(-60 * 24 * time.Hour)},
+		"b-daily": {Count: 10, Last: now.Add(-3 * time.Hour)},
+		"c-new":   {Count: 1, Last: now.Add(-time.Minute)},
+	}
+
+	tests := []struct {
+		order   string
+		want    []string
+		wantErr bool
+	}{
+		{order: config.SortFrecency, want: []string{"b-daily", "a-old", "c-new", "d-unused"}},
+		{order: config.SortRecent, want: []string{"c-new", "b-daily", "a-old", "d-unused"}},
+		{order: config.SortAlpha, want: []string{"a-old", "b-daily", "c-new", "d-unused"}},
+		{order: config.SortCluster, want: []string{"b-daily", "d-unused", "a-old", "c-new"}},
+		{order: "random", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			contexts := manager.ListContexts()
+			err := manager.SortContexts(contexts, tt.order, u)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SortContexts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !slices.Equal(contexts, tt.want) {
+				t.Errorf("SortContexts() = %v, want %v", contexts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// TagsExtension is the name of the context extension holding its tags
+const TagsExtension = "kubectl-ctx/tags"
+
+// Tags returns the tags of a context. Tags of the tool config apply first,
+// those of matching patterns in lexical order then those of the exact name,
+// followed by the context's TagsExtension, so the kubeconfig has the last
+// word.
+func (m *Manager) Tags(name string) (labels.Set, error) {
+	ctx, err := m.GetContext(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := m.toolConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := labels.Set{}
+	patterns := make([]string, 0, len(c.Tags))
+	for pattern := range c.Tags {
+		if pattern != name {
+			patterns = append(patterns, pattern)
+		}
+	}
+	slices.Sort(patterns)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			maps.Copy(tags, c.Tags[pattern])
+		}
+	}
+	maps.Copy(tags, c.Tags[name])
+
+	if extension, ok := ctx.Extensions[TagsExtension]; ok {
+		raw, err := json.Marshal(extension)
+		if err != nil {
+			return nil, err
+		}
+		var extensionTags map[string]string
+		if err := json.Unmarshal(raw, &extensionTags); err != nil {
+			return nil, fmt.Errorf("invalid %s extension of context %q in %s, expected a map of strings: %w",
+				TagsExtension, name, ctx.LocationOfOrigin, err)
+		}
+		maps.Copy(tags, extensionTags)
+	}
+
+	return tags, nil
+}
+
+// SelectContexts returns the contexts whose tags match a label selector
+// such as "env=prod,team!=infra"
+func (m *Manager) SelectContexts(contexts []string, selector string) ([]string, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	selected := make([]string, 0, len(contexts))
+	for _, name := range contexts {
+		tags, err := m.Tags(name)
+		if err != nil {
+			return nil, err
+		}
+		if s.Matches(tags) {
+			selected = append(selected, name)
+		}
+	}
+	return selected, nil
+}
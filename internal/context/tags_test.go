@@ -0,0 +1,97 @@
+package context
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestSelectContexts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := createTestKubeconfig(t, []string{"prod-payments", "prod-infra", "staging", "dev"}, "dev")
+
+	// Tag contexts in the kubeconfig and in the config file
+	kc, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load test kubeconfig: %v", err)
+	}
+	kc.Contexts["prod-infra"].Extensions = map[string]runtime.Object{
+		TagsExtension: &runtime.Unknown{Raw: []byte(`{"team":"infra"}`)},
+	}
+	if err := clientcmd.WriteToFile(*kc, path); err != nil {
+		t.Fatalf("Failed to write test kubeconfig: %v", err)
+	}
+	testutil.WriteConfig(t, &config.Config{Tags: map[string]map[string]string{
+		"prod-*":        {"env": "prod", "team": "payments"},
+		"prod-payments": {"cloud": "aws"},
+		"staging":       {"env": "staging", "team": "payments"},
+	}})
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+
+	tags, err := manager.Tags("prod-infra")
+	if err != nil {
+		t.Fatalf("Tags() failed: %v", err)
+	}
+	if got := tags.String(); got != "env=prod,team=infra" {
+		t.Errorf("Tags() = %q, want the extension to override the config file", got)
+	}
+
+	tests := []struct {
+		selector string
+		want     []string
+		wantErr  bool
+	}{
+		{selector: "env=prod", want: []string{"prod-payments", "prod-infra"}},
+		{selector: "env=prod,team!=infra", want: []string{"prod-payments"}},
+		{selector: "team=payments", want: []string{"prod-payments", "staging"}},
+		{selector: "cloud", want: []string{"prod-payments"}},
+		{selector: "!env", want: []string{"dev"}},
+		{selector: "env in (prod,staging),team notin (infra)", want: []string{"prod-payments", "staging"}},
+		{selector: "env in (prod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := manager.SelectContexts([]string{"prod-payments", "prod-infra", "staging", "dev"}, tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectContexts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !slices.Equal(got, tt.want) {
+				t.Errorf("SelectContexts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTags_InvalidExtension(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := createTestKubeconfig(t, []string{"ctx1"}, "ctx1")
+
+	kc, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load test kubeconfig: %v", err)
+	}
+	kc.Contexts["ctx1"].Extensions = map[string]runtime.Object{
+		TagsExtension: &runtime.Unknown{Raw: []byte(`["env=prod"]`)},
+	}
+	if err := clientcmd.WriteToFile(*kc, path); err != nil {
+		t.Fatalf("Failed to write test kubeconfig: %v", err)
+	}
+
+	manager, err := NewManager(kubeconfig.NewConfigFlags())
+	if err != nil {
+		t.Fatalf("NewManager(kubeconfig.NewConfigFlags()) failed: %v", err)
+	}
+	if _, err := manager.Tags("ctx1"); err == nil {
+		t.Error("Tags() succeeded, want an error for a list extension")
+	}
+}
@@ -0,0 +1,182 @@
+package credentials
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Kinds of credentials an expiry is reported for
+const (
+	KindClientCertificate = "client-certificate"
+	KindToken             = "token"
+	KindAuthProvider      = "auth-provider"
+	KindExec              = "exec"
+)
+
+// SourceInline marks credentials embedded in the kubeconfig
+const SourceInline = "inline"
+
+// DefaultWarningWindow is how long before expiry switching warns
+const DefaultWarningWindow = 7 * 24 * time.Hour
+
+// Expiry is the expiry of one credential of a context's user. Expiry is nil
+// when the credential has no known expiry.
+type Expiry struct {
+	Context string     `json:"context"`
+	User    string     `json:"user"`
+	Kind    string     `json:"kind"`
+	Source  string     `json:"source,omitempty"`
+	Expiry  *time.Time `json:"expiry,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// ExpiresWithin reports whether the credential expires (or has expired)
+// before now+window
+func (e Expiry) ExpiresWithin(now time.Time, window time.Duration) bool {
+	return e.Expiry != nil && e.Expiry.Before(now.Add(window))
+}
+
+// Refreshes reports whether the credential is renewed on use, as exec plugins
+// and auth-providers do, so that its expiry needs no attention
+func (e Expiry) Refreshes() bool {
+	return e.Kind == KindExec || e.Kind == KindAuthProvider
+}
+
+// Expiries returns the expiry of each credential of a context's user: client
+// certificate, bearer token (JWT exp), auth-provider id-token, and the token
+// cached by known exec plugins
+func Expiries(config *api.Config, contextName string) []Expiry {
+	ctx, exists := config.Contexts[contextName]
+	if !exists {
+		return nil
+	}
+	authInfo, exists := config.AuthInfos[ctx.AuthInfo]
+	if !exists {
+		return nil
+	}
+
+	var expiries []Expiry
+	add := func(kind, source string, expiry *time.Time, err error) {
+		e := Expiry{Context: contextName, User: ctx.AuthInfo, Kind: kind, Source: source, Expiry: expiry}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		expiries = append(expiries, e)
+	}
+
+	switch {
+	case len(authInfo.ClientCertificateData) > 0:
+		expiry, err := certificateExpiry(authInfo.ClientCertificateData)
+		add(KindClientCertificate, SourceInline, expiry, err)
+	case authInfo.ClientCertificate != "":
+		content, err := os.ReadFile(authInfo.ClientCertificate)
+		var expiry *time.Time
+		if err == nil {
+			expiry, err = certificateExpiry(content)
+		}
+		add(KindClientCertificate, authInfo.ClientCertificate, expiry, err)
+	}
+
+	switch {
+	case authInfo.Token != "":
+		add(KindToken, SourceInline, tokenExpiry(authInfo.Token), nil)
+	case authInfo.TokenFile != "":
+		content, err := os.ReadFile(authInfo.TokenFile)
+		add(KindToken, authInfo.TokenFile, tokenExpiry(strings.TrimSpace(string(content))), err)
+	}
+
+	if authInfo.AuthProvider != nil {
+		add(KindAuthProvider, authInfo.AuthProvider.Name, authProviderExpiry(authInfo.AuthProvider.Config), nil)
+	}
+
+	if authInfo.Exec != nil {
+		expiry, source, err := execCacheExpiry(authInfo.Exec)
+		add(KindExec, source, expiry, err)
+	}
+
+	return expiries
+}
+
+// certificateExpiry returns the NotAfter of the first PEM certificate
+func certificateExpiry(content []byte) (*time.Time, error) {
+	block, _ := pem.Decode(content)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return &cert.NotAfter, nil
+}
+
+func tokenExpiry(token string) *time.Time {
+	if expiry, ok := TokenExpiry(token); ok {
+		return &expiry
+	}
+	return nil
+}
+
+// authProviderExpiry reads the id-token of the oidc provider, or the expiry
+// stored by the legacy gcp and azure providers
+func authProviderExpiry(config map[string]string) *time.Time {
+	if expiry := tokenExpiry(config["id-token"]); expiry != nil {
+		return expiry
+	}
+	if expiry, err := time.Parse(time.RFC3339Nano, config["expiry"]); err == nil {
+		return &expiry
+	}
+	if seconds, err := strconv.ParseInt(config["expires-on"], 10, 64); err == nil {
+		expiry := time.Unix(seconds, 0)
+		return &expiry
+	}
+	return nil
+}
+
+// gkeCache is the token cache of gke-gcloud-auth-plugin
+type gkeCache struct {
+	TokenExpiry time.Time `json:"token_expiry"`
+}
+
+// execCacheExpiry reads the expiry of the token cached by known exec
+// plugins. Other plugins don't cache tokens where they can be found, so their
+// expiry is unknown.
+func execCacheExpiry(exec *api.ExecConfig) (*time.Time, string, error) {
+	command := strings.TrimSuffix(filepath.Base(exec.Command), ".exe")
+	if command != "gke-gcloud-auth-plugin" {
+		return nil, command, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, command, err
+	}
+	path := filepath.Join(home, ".kube", "gke_gcloud_auth_plugin_cache")
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, path, nil // No token obtained yet
+	}
+	if err != nil {
+		return nil, path, err
+	}
+
+	var cache gkeCache
+	if err := json.Unmarshal(content, &cache); err != nil {
+		return nil, path, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cache.TokenExpiry.IsZero() {
+		return nil, path, nil
+	}
+	return &cache.TokenExpiry, path, nil
+}
@@ -0,0 +1,102 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestExpiries(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	certExpiry := time.Now().Add(48 * time.Hour).Truncate(time.Second).UTC()
+	tokenExpiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	gkeExpiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	require.NoError(t, os.WriteFile(certFile, testutil.NewCertificate(t, certExpiry), 0600))
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte(testutil.NewJWT(tokenExpiry)+"\n"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".kube"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".kube", "gke_gcloud_auth_plugin_cache"),
+		[]byte(`{"current_context":"gke","access_token":"x","token_expiry":"2030-01-02T03:04:05Z"}`), 0600))
+
+	config := api.NewConfig()
+	users := map[string]*api.AuthInfo{
+		"inline-cert": {ClientCertificateData: testutil.NewCertificate(t, certExpiry)},
+		"file-cert":   {ClientCertificate: certFile},
+		"bad-cert":    {ClientCertificateData: []byte("garbage")},
+		"jwt":         {Token: testutil.NewJWT(tokenExpiry)},
+		"token-file":  {TokenFile: tokenFile},
+		"opaque":      {Token: "opaque"},
+		"oidc":        {AuthProvider: &api.AuthProviderConfig{Name: "oidc", Config: map[string]string{"id-token": testutil.NewJWT(tokenExpiry)}}},
+		"gke":         {Exec: &api.ExecConfig{Command: "/usr/bin/gke-gcloud-auth-plugin"}},
+		"aws":         {Exec: &api.ExecConfig{Command: "aws"}},
+	}
+	for name, user := range users {
+		config.AuthInfos[name] = user
+		config.Contexts[name] = &api.Context{AuthInfo: name}
+	}
+
+	tests := []struct {
+		context string
+		kind    string
+		source  string
+		expiry  *time.Time
+		err     bool
+	}{
+		{context: "inline-cert", kind: KindClientCertificate, source: SourceInline, expiry: &certExpiry},
+		{context: "file-cert", kind: KindClientCertificate, source: certFile, expiry: &certExpiry},
+		{context: "bad-cert", kind: KindClientCertificate, source: SourceInline, err: true},
+		{context: "jwt", kind: KindToken, source: SourceInline, expiry: &tokenExpiry},
+		{context: "token-file", kind: KindToken, source: tokenFile, expiry: &tokenExpiry},
+		{context: "opaque", kind: KindToken, source: SourceInline},
+		{context: "oidc", kind: KindAuthProvider, source: "oidc", expiry: &tokenExpiry},
+		{context: "gke", kind: KindExec, source: filepath.Join(home, ".kube", "gke_gcloud_auth_plugin_cache"), expiry: &gkeExpiry},
+		{context: "aws", kind: KindExec, source: "aws"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.context, func(t *testing.T) {
+			expiries := Expiries(config, tt.context)
+			require.Len(t, expiries, 1)
+			e := expiries[0]
+			assert.Equal(t, tt.context, e.User)
+			assert.Equal(t, tt.kind, e.Kind)
+			assert.Equal(t, tt.source, e.Source)
+			assert.Equal(t, tt.err, e.Error != "", e.Error)
+			if tt.expiry == nil {
+				assert.Nil(t, e.Expiry)
+				return
+			}
+			require.NotNil(t, e.Expiry)
+			assert.True(t, tt.expiry.Equal(*e.Expiry), "expiry %v, want %v", e.Expiry, tt.expiry)
+		})
+	}
+
+	assert.Empty(t, Expiries(config, "unknown"))
+}
+
+func TestExpiresWithin(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(time.Hour)
+
+	assert.True(t, Expiry{Expiry: &soon}.ExpiresWithin(now, 2*time.Hour))
+	assert.False(t, Expiry{Expiry: &soon}.ExpiresWithin(now, 30*time.Minute))
+	assert.False(t, Expiry{}.ExpiresWithin(now, time.Hour))
+}
+
+func TestRefreshes(t *testing.T) {
+	assert.True(t, Expiry{Kind: KindExec}.Refreshes())
+	assert.True(t, Expiry{Kind: KindAuthProvider}.Refreshes())
+	assert.False(t, Expiry{Kind: KindClientCertificate}.Refreshes())
+	assert.False(t, Expiry{Kind: KindToken}.Refreshes())
+}
@@ -0,0 +1,103 @@
+// Package credentials inspects and refreshes the credentials of contexts
+package credentials
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	// Register the oidc auth-provider, the one still supported by client-go
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Credential methods of a context's user
+const (
+	MethodExec         = "exec"
+	MethodAuthProvider = "auth-provider"
+	MethodStatic       = "static"
+)
+
+// LoginResult is the outcome of pre-warming the credentials of a context
+type LoginResult struct {
+	Context       string     `json:"context"`
+	Method        string     `json:"method"`
+	ServerVersion string     `json:"serverVersion,omitempty"`
+	Expiry        *time.Time `json:"expiry,omitempty"`
+}
+
+// Method returns how the user of a context obtains credentials
+func Method(authInfo *api.AuthInfo) string {
+	switch {
+	case authInfo == nil:
+		return MethodStatic
+	case authInfo.Exec != nil:
+		return MethodExec
+	case authInfo.AuthProvider != nil:
+		return MethodAuthProvider
+	default:
+		return MethodStatic
+	}
+}
+
+// Login runs the exec plugin or auth-provider of a context through
+// client-go, including any interactive or browser login, by sending an
+// authenticated request to its server. The expiry is reported when the
+// obtained bearer token is a JWT. Tokens refreshed by an auth-provider are
+// written back through configAccess. Contexts with static credentials are
+// skipped.
+func Login(config api.Config, contextName string, overrides *clientcmd.ConfigOverrides, configAccess clientcmd.ConfigAccess) (*LoginResult, error) {
+	ctx, exists := config.Contexts[contextName]
+	if !exists {
+		return nil, fmt.Errorf("context %q not found", contextName)
+	}
+
+	result := &LoginResult{Context: contextName, Method: Method(config.AuthInfos[ctx.AuthInfo])}
+	if result.Method == MethodStatic {
+		slog.Debug("No credential plugin to run", "context", contextName)
+		return result, nil
+	}
+
+	restConfig, err := clientcmd.NewInteractiveClientConfig(config, contextName, overrides, os.Stdin, configAccess).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// The authenticator wraps the transport outside of this wrapper, so the
+	// request seen here carries the credentials it obtained
+	var token string
+	restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if auth, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); found {
+				token = auth
+			}
+			return rt.RoundTrip(req)
+		})
+	})
+
+	client, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	version, err := client.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	result.ServerVersion = version.GitVersion
+
+	if expiry, ok := TokenExpiry(token); ok {
+		result.Expiry = &expiry
+	}
+	return result, nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,124 @@
+package credentials
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// execConfig returns a config whose context "eks" uses the given exec plugin
+// to authenticate to a fake API server
+func execConfig(t *testing.T, plugin string) api.Config {
+	server, ca := testutil.NewFakeTLSAPIServer(t, nil)
+
+	config := api.NewConfig()
+	config.Clusters["eks"] = &api.Cluster{Server: server, CertificateAuthorityData: ca}
+	config.AuthInfos["eks"] = &api.AuthInfo{Exec: &api.ExecConfig{
+		APIVersion:      "client.authentication.k8s.io/v1",
+		Command:         plugin,
+		InteractiveMode: api.NeverExecInteractiveMode,
+	}}
+	config.AuthInfos["static"] = &api.AuthInfo{Token: "token"}
+	config.Contexts["eks"] = &api.Context{Cluster: "eks", AuthInfo: "eks"}
+	config.Contexts["static"] = &api.Context{Cluster: "eks", AuthInfo: "static"}
+	return *config
+}
+
+func TestLogin_ExecPlugin(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	plugin, calls := testutil.NewExecPlugin(t, testutil.NewJWT(expiry))
+	config := execConfig(t, plugin)
+
+	result, err := Login(config, "eks", &clientcmd.ConfigOverrides{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, MethodExec, result.Method)
+	assert.Equal(t, "v1.36.3", result.ServerVersion)
+	require.NotNil(t, result.Expiry)
+	assert.True(t, expiry.Equal(*result.Expiry))
+	assert.FileExists(t, calls)
+}
+
+func TestLogin_Failure(t *testing.T) {
+	plugin, _ := testutil.NewExecPlugin(t, "")
+	config := execConfig(t, plugin)
+
+	_, err := Login(config, "eks", &clientcmd.ConfigOverrides{}, nil)
+	assert.Error(t, err)
+}
+
+func TestLogin_PersistsRefreshedToken(t *testing.T) {
+	server, ca := testutil.NewFakeTLSAPIServer(t, nil)
+	idToken := testutil.NewJWT(time.Now().Add(time.Hour).Truncate(time.Second))
+
+	// An OIDC provider answering the refresh of the expired id-token
+	var issuer string
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_, _ = fmt.Fprintf(w, `{"token_endpoint": %q}`, issuer+"/token")
+		case "/token":
+			_, _ = fmt.Fprintf(w, `{"access_token": "access", "token_type": "Bearer", "id_token": %q, "refresh_token": "refresh-2"}`, idToken)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(provider.Close)
+	issuer = provider.URL
+
+	config := api.NewConfig()
+	config.Clusters["oidc"] = &api.Cluster{Server: server, CertificateAuthorityData: ca}
+	config.AuthInfos["oidc"] = &api.AuthInfo{AuthProvider: &api.AuthProviderConfig{Name: "oidc", Config: map[string]string{
+		"idp-issuer-url": issuer,
+		"client-id":      "kubectl",
+		"id-token":       testutil.NewJWT(time.Now().Add(-time.Hour)),
+		"refresh-token":  "refresh-1",
+	}}}
+	config.Contexts["oidc"] = &api.Context{Cluster: "oidc", AuthInfo: "oidc"}
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, clientcmd.WriteToFile(*config, path))
+
+	result, err := Login(*config, "oidc", &clientcmd.ConfigOverrides{}, &clientcmd.ClientConfigLoadingRules{ExplicitPath: path})
+	require.NoError(t, err)
+	assert.Equal(t, MethodAuthProvider, result.Method)
+
+	written, err := clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, idToken, written.AuthInfos["oidc"].AuthProvider.Config["id-token"])
+	assert.Equal(t, "refresh-2", written.AuthInfos["oidc"].AuthProvider.Config["refresh-token"])
+}
+
+func TestLogin_StaticCredentials(t *testing.T) {
+	plugin, calls := testutil.NewExecPlugin(t, "token")
+	config := execConfig(t, plugin)
+
+	result, err := Login(config, "static", &clientcmd.ConfigOverrides{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, MethodStatic, result.Method)
+	assert.NoFileExists(t, calls)
+
+	_, err = Login(config, "unknown", &clientcmd.ConfigOverrides{}, nil)
+	assert.Error(t, err)
+}
+
+func TestTokenExpiry(t *testing.T) {
+	expiry := time.Unix(1893456000, 0)
+
+	got, ok := TokenExpiry(testutil.NewJWT(expiry))
+	require.True(t, ok)
+	assert.True(t, expiry.Equal(got))
+
+	_, ok = TokenExpiry("opaque-token")
+	assert.False(t, ok)
+	_, ok = TokenExpiry("a.b.c")
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+// Package hooks runs user executables before and after a switch
+package hooks
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/utils/paths"
+)
+
+// Phases of a switch hooks run in
+const (
+	PhasePre  = "pre"
+	PhasePost = "post"
+)
+
+// Environment variables configuring hook executables (path-list separated)
+const (
+	EnvPreHook  = "KUBECTL_CTX_PRE_HOOK"
+	EnvPostHook = "KUBECTL_CTX_POST_HOOK"
+)
+
+// Prefix is the name prefix of hook executables discovered on PATH; these
+// run in both phases
+const Prefix = "kubectl-ctx-hook-"
+
+// Event describes a switch to hooks
+type Event struct {
+	// Kind is "context" or "namespace"
+	Kind         string
+	OldContext   string
+	NewContext   string
+	OldNamespace string
+	NewNamespace string
+	OldServer    string
+	NewServer    string
+}
+
+// Env returns the environment variables passing the event to a hook
+func (e Event) Env(phase string) []string {
+	return []string{
+		"KUBECTL_CTX_HOOK_PHASE=" + phase,
+		"KUBECTL_CTX_HOOK_KIND=" + e.Kind,
+		"KUBECTL_CTX_OLD_CONTEXT=" + e.OldContext,
+		"KUBECTL_CTX_NEW_CONTEXT=" + e.NewContext,
+		"KUBECTL_CTX_OLD_NAMESPACE=" + e.OldNamespace,
+		"KUBECTL_CTX_NEW_NAMESPACE=" + e.NewNamespace,
+		"KUBECTL_CTX_OLD_SERVER=" + e.OldServer,
+		"KUBECTL_CTX_NEW_SERVER=" + e.NewServer,
+	}
+}
+
+// Discover returns the hooks for a phase: the configured ones first, from
+// the environment variable of the phase or else the tool config, then
+// kubectl-ctx-hook-* executables on PATH sorted by name. Like kubectl
+// plugins, the first executable on PATH wins for each name.
+func Discover(phase string, c *config.Config) ([]string, error) {
+	env, configured := EnvPreHook, c.Hooks.Pre
+	if phase == PhasePost {
+		env, configured = EnvPostHook, c.Hooks.Post
+	}
+	if value := os.Getenv(env); value != "" {
+		configured = filepath.SplitList(value)
+	}
+
+	var hooks []string
+	for _, hook := range configured {
+		if hook == "" {
+			continue
+		}
+		hook, err := paths.ExpandHome(hook)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	seen := map[string]bool{}
+	var found []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, Prefix) || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			found = append(found, path)
+		}
+	}
+	slices.SortFunc(found, func(a, b string) int { return strings.Compare(filepath.Base(a), filepath.Base(b)) })
+
+	return append(hooks, found...), nil
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+// Run runs the hooks of a phase in order. A failing pre hook aborts the
+// remaining hooks and the switch; post hook failures are only logged.
+// Hook output goes to stderr, keeping stdout for results.
+func Run(phase string, c *config.Config, event Event) error {
+	hooks, err := Discover(phase, c)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		slog.Debug("Running hook", "phase", phase, "hook", hook)
+
+		cmd := exec.Command(hook)
+		cmd.Env = append(os.Environ(), event.Env(phase)...)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			if phase == PhasePre {
+				return fmt.Errorf("pre-switch hook %s failed, aborting switch: %w", hook, err)
+			}
+			slog.Warn("Post-switch hook failed", "hook", hook, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHook writes an executable shell script
+func writeHook(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0700))
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	b := writeHook(t, dir1, Prefix+"b", "true")
+	a := writeHook(t, dir2, Prefix+"a", "true")
+	writeHook(t, dir2, Prefix+"b", "true") // shadowed by dir1
+	require.NoError(t, os.WriteFile(filepath.Join(dir1, Prefix+"not-executable"), nil, 0600))
+	writeHook(t, dir1, "other", "true")
+
+	configured := writeHook(t, t.TempDir(), "configured", "true")
+	t.Setenv("PATH", dir1+string(os.PathListSeparator)+dir2)
+	t.Setenv(EnvPreHook, configured)
+	t.Setenv(EnvPostHook, "")
+
+	// The environment overrides the config file
+	fromConfig := writeHook(t, t.TempDir(), "from-config", "true")
+	c := &config.Config{Hooks: config.Hooks{Pre: []string{"overridden"}, Post: []string{fromConfig}}}
+
+	hooks, err := Discover(PhasePre, c)
+	require.NoError(t, err)
+	assert.Equal(t, []string{configured, a, b}, hooks)
+	hooks, err = Discover(PhasePost, c)
+	require.NoError(t, err)
+	assert.Equal(t, []string{fromConfig, a, b}, hooks)
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	writeHook(t, dir, Prefix+"record", `echo "$KUBECTL_CTX_HOOK_PHASE $KUBECTL_CTX_OLD_CONTEXT $KUBECTL_CTX_NEW_CONTEXT $KUBECTL_CTX_NEW_NAMESPACE $KUBECTL_CTX_NEW_SERVER" >> `+out)
+	t.Setenv("PATH", dir)
+	t.Setenv(EnvPreHook, "")
+	t.Setenv(EnvPostHook, "")
+
+	event := Event{Kind: "context", OldContext: "dev", NewContext: "prod", NewNamespace: "web", NewServer: "https://prod:6443"}
+	require.NoError(t, Run(PhasePre, &config.Config{}, event))
+	require.NoError(t, Run(PhasePost, &config.Config{}, event))
+
+	content, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "pre dev prod web https://prod:6443\npost dev prod web https://prod:6443\n", string(content))
+}
+
+func TestRun_Failures(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	t.Setenv(EnvPreHook, writeHook(t, t.TempDir(), "deny", "exit 3"))
+	t.Setenv(EnvPostHook, writeHook(t, t.TempDir(), "broken", "exit 1"))
+
+	err := Run(PhasePre, &config.Config{}, Event{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "aborting switch")
+
+	// Post hook failures are only logged
+	assert.NoError(t, Run(PhasePost, &config.Config{}, Event{}))
+}
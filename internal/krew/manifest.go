@@ -0,0 +1,170 @@
+// Package krew generates krew plugin manifests for the release archives
+package krew
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ReleaseURL is the download URL of the release assets, formatted with the
+// version and the archive name
+const ReleaseURL = "https://github.com/camaeel/kubectl-ctx/releases/download/%s/%s"
+
+// Platform is an os/arch combination a release archive is built for
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// Platforms lists the release archives, matching the CI build matrix
+var Platforms = []Platform{
+	{OS: "linux", Arch: "amd64"},
+	{OS: "linux", Arch: "arm64"},
+	{OS: "darwin", Arch: "amd64"},
+	{OS: "darwin", Arch: "arm64"},
+	{OS: "windows", Arch: "amd64"},
+}
+
+// Plugin describes a krew plugin served by the kubectl-ctx binary
+type Plugin struct {
+	Name             string
+	Binary           string
+	ShortDescription string
+	Description      string
+}
+
+// Plugins are the plugins the multi-call binary provides, by name
+var Plugins = map[string]Plugin{
+	"ctx": {
+		Name:             "ctx",
+		Binary:           "kubectl-ctx",
+		ShortDescription: "Switch between contexts in your kubeconfig",
+		Description: "Switches the current context, optionally together with its namespace,\n" +
+			"interactively or by name. Handles multiple KUBECONFIG files, backs up\n" +
+			"every change and can undo it.\n",
+	},
+	"ns": {
+		Name:             "ns",
+		Binary:           "kubectl-ns",
+		ShortDescription: "Switch between Kubernetes namespaces",
+		Description: "Switches the namespace of the current context, interactively from the\n" +
+			"namespaces of the cluster or by name. Handles multiple KUBECONFIG files.\n",
+	},
+}
+
+// Manifest is a krew plugin manifest (krew.googlecontainertools.github.com/v1alpha2)
+type Manifest struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+	Spec       Spec     `json:"spec"`
+}
+
+// Metadata names the plugin
+type Metadata struct {
+	Name string `json:"name"`
+}
+
+// Spec describes the plugin and its per-platform archives
+type Spec struct {
+	Version          string             `json:"version"`
+	Homepage         string             `json:"homepage"`
+	ShortDescription string             `json:"shortDescription"`
+	Description      string             `json:"description"`
+	Platforms        []PlatformManifest `json:"platforms"`
+}
+
+// PlatformManifest is the archive to install on one platform
+type PlatformManifest struct {
+	Selector Selector        `json:"selector"`
+	URI      string          `json:"uri"`
+	SHA256   string          `json:"sha256"`
+	Files    []FileOperation `json:"files"`
+	Bin      string          `json:"bin"`
+}
+
+// Selector matches the os and arch labels of a platform
+type Selector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
+}
+
+// FileOperation copies a file out of the archive
+type FileOperation struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ArchiveName returns the name of the release archive for a platform
+func ArchiveName(p Platform) string {
+	return fmt.Sprintf("kubectl-ctx-%s-%s.tar.gz", p.OS, p.Arch)
+}
+
+// NewManifest returns the manifest of plugin for a release version.
+// checksums maps archive names to their SHA-256 sums and must cover all
+// Platforms. The archives contain the kubectl-ctx binary, which is installed
+// under the plugin's binary name so it picks the matching command.
+func NewManifest(plugin Plugin, version string, checksums map[string]string) (*Manifest, error) {
+	m := &Manifest{
+		APIVersion: "krew.googlecontainertools.github.com/v1alpha2",
+		Kind:       "Plugin",
+		Metadata:   Metadata{Name: plugin.Name},
+		Spec: Spec{
+			Version:          version,
+			Homepage:         "https://github.com/camaeel/kubectl-ctx",
+			ShortDescription: plugin.ShortDescription,
+			Description:      plugin.Description,
+		},
+	}
+
+	for _, p := range Platforms {
+		archive := ArchiveName(p)
+		sum, ok := checksums[archive]
+		if !ok {
+			return nil, fmt.Errorf("no checksum for %s", archive)
+		}
+
+		ext := ""
+		if p.OS == "windows" {
+			ext = ".exe"
+		}
+
+		m.Spec.Platforms = append(m.Spec.Platforms, PlatformManifest{
+			Selector: Selector{MatchLabels: map[string]string{"os": p.OS, "arch": p.Arch}},
+			URI:      fmt.Sprintf(ReleaseURL, version, archive),
+			SHA256:   sum,
+			Files: []FileOperation{
+				{From: "kubectl-ctx" + ext, To: plugin.Binary + ext},
+				{From: "LICENSE", To: "."},
+			},
+			Bin: plugin.Binary + ext,
+		})
+	}
+
+	return m, nil
+}
+
+// ParseChecksums reads sha256sum output into a map of file base names to sums
+func ParseChecksums(r io.Reader) (map[string]string, error) {
+	checksums := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid checksum line %q", scanner.Text())
+		}
+		// A leading "*" marks files hashed in binary mode
+		checksums[filepath.Base(strings.TrimPrefix(fields[1], "*"))] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksums: %w", err)
+	}
+
+	return checksums, nil
+}
@@ -0,0 +1,48 @@
+package krew
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecksums(t *testing.T) {
+	checksums, err := ParseChecksums(strings.NewReader(
+		"aaa  ./kubectl-ctx-linux-amd64.tar.gz\n\nbbb *dist/kubectl-ctx-windows-amd64.tar.gz\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"kubectl-ctx-linux-amd64.tar.gz":   "aaa",
+		"kubectl-ctx-windows-amd64.tar.gz": "bbb",
+	}, checksums)
+
+	_, err = ParseChecksums(strings.NewReader("not a checksum line\n"))
+	assert.Error(t, err)
+}
+
+func TestNewManifest(t *testing.T) {
+	checksums := map[string]string{}
+	for _, p := range Platforms {
+		checksums[ArchiveName(p)] = p.OS + p.Arch
+	}
+
+	m, err := NewManifest(Plugins["ns"], "v1.2.3", checksums)
+	require.NoError(t, err)
+	assert.Equal(t, "ns", m.Metadata.Name)
+	require.Len(t, m.Spec.Platforms, len(Platforms))
+
+	for _, p := range m.Spec.Platforms {
+		if p.Selector.MatchLabels["os"] != "windows" {
+			continue
+		}
+		assert.Equal(t, "https://github.com/camaeel/kubectl-ctx/releases/download/v1.2.3/kubectl-ctx-windows-amd64.tar.gz", p.URI)
+		assert.Equal(t, "windowsamd64", p.SHA256)
+		assert.Equal(t, "kubectl-ns.exe", p.Bin)
+		assert.Contains(t, p.Files, FileOperation{From: "kubectl-ctx.exe", To: "kubectl-ns.exe"})
+	}
+
+	delete(checksums, ArchiveName(Platforms[0]))
+	_, err = NewManifest(Plugins["ctx"], "v1.2.3", checksums)
+	assert.Error(t, err)
+}
@@ -0,0 +1,220 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// FileChange is the content of a kubeconfig file before and after a change
+type FileChange struct {
+	Path   string
+	Before []byte
+	After  []byte
+}
+
+// UnifiedDiff returns the change as a unified diff
+func (c FileChange) UnifiedDiff() (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(c.Before),
+		B:        splitLines(c.After),
+		FromFile: "a" + c.Path,
+		ToFile:   "b" + c.Path,
+		Context:  3,
+	})
+}
+
+// splitLines splits content into lines keeping their line endings
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// ReportDiffs returns a DryRun option reporting a unified diff per changed
+// file through report
+func ReportDiffs(report func(path, diff string) error) func(changes []FileChange) error {
+	return func(changes []FileChange) error {
+		for _, c := range changes {
+			diff, err := c.UnifiedDiff()
+			if err != nil {
+				return err
+			}
+			if err := report(c.Path, diff); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Changes computes, in memory, the content ModifyConfig would write to each
+// file when turning the before configuration into the after configuration.
+// Files are returned sorted by path; unchanged files are omitted.
+func Changes(configAccess clientcmd.ConfigAccess, before, after *api.Config) ([]FileChange, error) {
+	files := map[string]*api.Config{}
+	originals := map[string][]byte{}
+
+	// load returns the in-memory copy of a file, reading it on first use
+	load := func(path string) (*api.Config, error) {
+		if path == "" {
+			path = configAccess.GetDefaultFilename()
+		}
+		if config, ok := files[path]; ok {
+			return config, nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		config := api.NewConfig()
+		if len(content) > 0 {
+			if config, err = clientcmd.Load(content); err != nil {
+				return nil, fmt.Errorf("failed to load %s: %w", path, err)
+			}
+		}
+
+		files[path] = config
+		originals[path] = content
+		return config, nil
+	}
+
+	if before.CurrentContext != after.CurrentContext {
+		path, err := currentContextFile(configAccess, after.CurrentContext)
+		if err != nil {
+			return nil, err
+		}
+		config, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		config.CurrentContext = after.CurrentContext
+	}
+
+	if !reflect.DeepEqual(before.Preferences, after.Preferences) {
+		path := configAccess.GetDefaultFilename()
+		if configAccess.IsExplicitFile() {
+			path = configAccess.GetExplicitFile()
+		}
+		config, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		config.Preferences = after.Preferences
+	}
+
+	err := applyChanges(before.Clusters, after.Clusters, load,
+		func(c *api.Cluster) string { return c.LocationOfOrigin },
+		func(config *api.Config, name string, c *api.Cluster) { config.Clusters[name] = c },
+		func(config *api.Config, name string) { delete(config.Clusters, name) })
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyChanges(before.Contexts, after.Contexts, load,
+		func(c *api.Context) string { return c.LocationOfOrigin },
+		func(config *api.Config, name string, c *api.Context) { config.Contexts[name] = c },
+		func(config *api.Config, name string) { delete(config.Contexts, name) })
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyChanges(before.AuthInfos, after.AuthInfos, load,
+		func(a *api.AuthInfo) string { return a.LocationOfOrigin },
+		func(config *api.Config, name string, a *api.AuthInfo) { config.AuthInfos[name] = a },
+		func(config *api.Config, name string) { delete(config.AuthInfos, name) })
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]FileChange, 0, len(files))
+	for path, config := range files {
+		content, err := clientcmd.Write(*config)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, FileChange{Path: path, Before: originals[path], After: content})
+	}
+
+	slices.SortFunc(changes, func(a, b FileChange) int { return strings.Compare(a.Path, b.Path) })
+	return changes, nil
+}
+
+// currentContextFile mirrors where ModifyConfig writes current-context: the
+// default file, or when clearing it, the first file that sets it
+func currentContextFile(configAccess clientcmd.ConfigAccess, currentContext string) (string, error) {
+	if configAccess.IsExplicitFile() {
+		return configAccess.GetExplicitFile(), nil
+	}
+	if currentContext != "" {
+		return configAccess.GetDefaultFilename(), nil
+	}
+
+	for _, path := range configAccess.GetLoadingPrecedence() {
+		config, err := clientcmd.LoadFromFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if config.CurrentContext != "" {
+			return path, nil
+		}
+	}
+	return "", errors.New("no config found to write context")
+}
+
+// applyChanges copies entries added or modified in after, and removes entries
+// missing from after, in the in-memory copies of their files of origin
+func applyChanges[T any](
+	before, after map[string]T,
+	load func(path string) (*api.Config, error),
+	origin func(T) string,
+	set func(config *api.Config, name string, value T),
+	remove func(config *api.Config, name string),
+) error {
+	for _, name := range sortedKeys(after) {
+		value := after[name]
+		if old, exists := before[name]; exists && reflect.DeepEqual(value, old) {
+			continue
+		}
+		config, err := load(origin(value))
+		if err != nil {
+			return err
+		}
+		set(config, name, value)
+	}
+
+	for _, name := range sortedKeys(before) {
+		if _, exists := after[name]; exists {
+			continue
+		}
+		config, err := load(origin(before[name]))
+		if err != nil {
+			return err
+		}
+		remove(config, name)
+	}
+	return nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
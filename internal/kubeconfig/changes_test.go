@@ -0,0 +1,110 @@
+package kubeconfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// TestChanges_MatchesModifyConfig checks that the in-memory rendering Update
+// writes and dry runs report is exactly what ModifyConfig writes
+func TestChanges_MatchesModifyConfig(t *testing.T) {
+	path1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": "", "shared": ""})
+	path2 := testutil.CreateKubeconfig(t, "ctx2", map[string]string{"ctx2": "old", "shared": "shadowed"})
+	t.Setenv("KUBECONFIG", path1+string(os.PathListSeparator)+path2)
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+
+	before, err := rules.Load()
+	require.NoError(t, err)
+	after := before.DeepCopy()
+	after.CurrentContext = "ctx2"
+	after.Contexts["ctx2"].Namespace = "new"
+	delete(after.Contexts, "shared")
+
+	changes, err := Changes(rules, before, after)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, []string{path1, path2}, []string{changes[0].Path, changes[1].Path})
+
+	for _, c := range changes {
+		original, err := os.ReadFile(c.Path)
+		require.NoError(t, err)
+		assert.Equal(t, string(original), string(c.Before))
+	}
+
+	require.NoError(t, clientcmd.ModifyConfig(rules, *after, false))
+	for _, c := range changes {
+		written, err := os.ReadFile(c.Path)
+		require.NoError(t, err)
+		assert.Equal(t, string(written), string(c.After), "dry run differs from ModifyConfig for %s", c.Path)
+	}
+}
+
+func TestChanges_NoChanges(t *testing.T) {
+	path := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": ""})
+	t.Setenv("KUBECONFIG", path)
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+
+	config, err := rules.Load()
+	require.NoError(t, err)
+
+	changes, err := Changes(rules, config, config.DeepCopy())
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	c := FileChange{
+		Path:   "/kube/config",
+		Before: []byte("a\ncurrent-context: dev\nb\n"),
+		After:  []byte("a\ncurrent-context: prod\nb\n"),
+	}
+
+	diff, err := c.UnifiedDiff()
+	require.NoError(t, err)
+	assert.Equal(t, `--- a/kube/config
++++ b/kube/config
+@@ -1,3 +1,3 @@
+ a
+-current-context: dev
++current-context: prod
+ b
+`, diff)
+}
+
+func TestUpdate_DryRun(t *testing.T) {
+	path := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": "", "ctx2": ""})
+	t.Setenv("KUBECONFIG", path)
+	original, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var reported []string
+	options := UpdateOptions{
+		DryRun: ReportDiffs(func(file, diff string) error {
+			reported = append(reported, file)
+			assert.Contains(t, diff, "+current-context: ctx2")
+			return nil
+		}),
+		BeforeWrite: func([]string) error {
+			t.Error("BeforeWrite must not be called in dry-run mode")
+			return nil
+		},
+	}
+
+	config, err := Update(clientcmd.NewDefaultClientConfigLoadingRules(), options, func(config *api.Config) error {
+		config.CurrentContext = "ctx2"
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", config.CurrentContext)
+	assert.Equal(t, []string{path}, reported)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(original), string(content), "dry run must not write")
+}
@@ -0,0 +1,84 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/camaeel/kubectl-ctx/internal/utils/paths"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// EnvDirs holds glob patterns of kubeconfig files to discover, separated like
+// PATH. It takes precedence over kubeconfigDirs in the tool config.
+const EnvDirs = "KUBECTL_CTX_KUBECONFIG_DIRS"
+
+// Sources of the files in the loading precedence
+const (
+	SourceFlag       = "--kubeconfig"
+	SourceEnv        = clientcmd.RecommendedConfigPathEnvVar
+	SourceDefault    = "default"
+	SourceDiscovered = "discovered"
+	SourceEnabled    = "enabled"
+)
+
+// FileSource is a file of the loading precedence and why it is loaded
+type FileSource struct {
+	Path   string `json:"path"`
+	Source string `json:"source"`
+	// Pattern is the glob pattern that discovered the file
+	Pattern string `json:"pattern,omitempty"`
+	// Disabled files were removed from the loading precedence with
+	// "files disable"
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// ActivePaths returns the paths of the files that are not disabled
+func ActivePaths(files []FileSource) []string {
+	var active []string
+	for _, f := range files {
+		if !f.Disabled {
+			active = append(active, f.Path)
+		}
+	}
+	return active
+}
+
+// DiscoveryPatterns returns the glob patterns of kubeconfig files to
+// discover, from KUBECTL_CTX_KUBECONFIG_DIRS or the tool config
+func DiscoveryPatterns(c *config.Config) []string {
+	if env := os.Getenv(EnvDirs); env != "" {
+		return filepath.SplitList(env)
+	}
+	return c.KubeconfigDirs
+}
+
+// Discover returns the files matching the glob patterns, sorted per pattern.
+// A leading ~ is expanded and directories are skipped.
+func Discover(patterns []string) ([]FileSource, error) {
+	var files []FileSource
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		expanded, err := paths.ExpandHome(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(expanded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig pattern %q: %w", pattern, err)
+		}
+		slices.Sort(matches)
+
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() {
+				continue
+			}
+			files = append(files, FileSource{Path: AbsPath(match), Source: SourceDiscovered, Pattern: pattern})
+		}
+	}
+	return files, nil
+}
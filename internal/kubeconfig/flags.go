@@ -0,0 +1,157 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ConfigFlags selects the kubeconfig files and overrides like kubectl does
+type ConfigFlags struct {
+	// KubeConfig is an explicit kubeconfig file replacing KUBECONFIG
+	KubeConfig string
+	Overrides  *clientcmd.ConfigOverrides
+	// Write holds the options of the kubeconfig writes made for the flags
+	Write UpdateOptions
+	// Config is the tool configuration, loaded on each use when nil
+	Config *config.Config
+}
+
+// NewConfigFlags returns flags using the default loading rules (KUBECONFIG
+// or ~/.kube/config) without overrides
+func NewConfigFlags() *ConfigFlags {
+	return &ConfigFlags{Overrides: &clientcmd.ConfigOverrides{}}
+}
+
+// LoadingRules returns the loading rules for the selected files. KUBECONFIG
+// and the discovery patterns are read on each call.
+func (f *ConfigFlags) LoadingRules() (*clientcmd.ClientConfigLoadingRules, error) {
+	files, err := f.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = f.KubeConfig
+	rules.Precedence = ActivePaths(files)
+	if len(rules.Precedence) == 0 && rules.ExplicitPath == "" {
+		return nil, fmt.Errorf("all kubeconfig files are disabled, enable one with \"files enable\"")
+	}
+	return rules, nil
+}
+
+// Files returns the loading precedence with the source of each file. Like
+// in kubectl, --kubeconfig selects a single file; otherwise the files
+// discovered or enabled by the tool follow KUBECONFIG (or ~/.kube/config),
+// so they never take precedence over files kubectl loads. Files disabled in
+// the tool config are included, marked as disabled.
+func (f *ConfigFlags) Files() ([]FileSource, error) {
+	if f.KubeConfig != "" {
+		return []FileSource{{Path: f.KubeConfig, Source: SourceFlag}}, nil
+	}
+
+	c, err := f.ToolConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []FileSource
+	seen := map[string]bool{}
+	add := func(file FileSource) {
+		key := AbsPath(file.Path)
+		if !seen[key] {
+			seen[key] = true
+			file.Disabled = slices.Contains(c.KubeconfigFiles.Disabled, key)
+			files = append(files, file)
+		}
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	source := SourceDefault
+	if os.Getenv(clientcmd.RecommendedConfigPathEnvVar) != "" {
+		source = SourceEnv
+	}
+	for _, path := range rules.Precedence {
+		add(FileSource{Path: path, Source: source})
+	}
+
+	discovered, err := Discover(DiscoveryPatterns(c))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range discovered {
+		add(file)
+	}
+
+	for _, path := range c.KubeconfigFiles.Enabled {
+		add(FileSource{Path: path, Source: SourceEnabled})
+	}
+
+	return files, nil
+}
+
+// ToolConfig returns the tool configuration, loading it unless it was set
+func (f *ConfigFlags) ToolConfig() (*config.Config, error) {
+	if f.Config != nil {
+		return f.Config, nil
+	}
+	return config.Load()
+}
+
+// CheckSwitch returns an error when kubectl wouldn't follow a switch to a
+// context defined in file. kubectl only loads KUBECONFIG (or
+// ~/.kube/config), not the files discovered or enabled by the tool, and
+// reads current-context from the first of them even when it is disabled.
+func (f *ConfigFlags) CheckSwitch(file string) error {
+	files, err := f.Files()
+	if err != nil {
+		return err
+	}
+
+	// current-context goes to the first active file, kubectl reads it from
+	// the first file it loads
+	if first := files[0]; first.Disabled && first.Source != SourceDiscovered && first.Source != SourceEnabled {
+		return fmt.Errorf("%s is disabled but kubectl still loads it, update KUBECONFIG with \"files export\" first", first.Path)
+	}
+
+	for _, source := range files {
+		if AbsPath(source.Path) == AbsPath(file) && (source.Source == SourceDiscovered || source.Source == SourceEnabled) {
+			return fmt.Errorf("kubectl doesn't load %s, add it to KUBECONFIG with \"files export\" first", file)
+		}
+	}
+	return nil
+}
+
+// AbsPath returns the absolute form of path, or path itself if it can't be
+// determined. Files are compared and stored in the tool config this way.
+func AbsPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// AddFlags adds kubectl's --kubeconfig, --context, --cluster and --user
+// flags. The --namespace override is left out, as both tools use -n for the
+// namespace to switch to.
+func (f *ConfigFlags) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.KubeConfig, clientcmd.RecommendedConfigPathFlag, "", "Path to the kubeconfig file to use")
+
+	overrideFlags := clientcmd.RecommendedConfigOverrideFlags("")
+	overrideFlags.CurrentContext.BindStringFlag(fs, &f.Overrides.CurrentContext)
+	overrideFlags.ContextOverrideFlags.ClusterName.BindStringFlag(fs, &f.Overrides.Context.Cluster)
+	overrideFlags.ContextOverrideFlags.AuthInfoName.BindStringFlag(fs, &f.Overrides.Context.AuthInfo)
+}
+
+// ContextOverrides returns the overrides to use when connecting through the
+// given context, which takes precedence over --context
+func (f *ConfigFlags) ContextOverrides() *clientcmd.ConfigOverrides {
+	overrides := *f.Overrides
+	overrides.CurrentContext = ""
+	return &overrides
+}
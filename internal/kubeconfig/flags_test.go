@@ -0,0 +1,96 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFlags(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/tmp/a:/tmp/b")
+
+	flags := NewConfigFlags()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.AddFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--kubeconfig", "/tmp/other", "--context", "prod", "--cluster", "c", "--user", "u"}))
+
+	assert.Nil(t, fs.Lookup("namespace"))
+	rules, err := flags.LoadingRules()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/tmp/other"}, rules.GetLoadingPrecedence())
+	assert.Equal(t, "prod", flags.Overrides.CurrentContext)
+
+	overrides := flags.ContextOverrides()
+	assert.Empty(t, overrides.CurrentContext)
+	assert.Equal(t, "c", overrides.Context.Cluster)
+	assert.Equal(t, "u", overrides.Context.AuthInfo)
+	assert.Equal(t, "prod", flags.Overrides.CurrentContext, "overrides must not be modified")
+}
+
+func TestConfigFlags_Defaults(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/tmp/a:/tmp/b")
+
+	rules, err := NewConfigFlags().LoadingRules()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/tmp/a", "/tmp/b"}, rules.GetLoadingPrecedence())
+}
+
+func TestConfigFlags_Discovery(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"kind.yaml", "k3d.yaml", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "dir.yaml"), 0700))
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "kubectl-ctx"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "kubectl-ctx", "config.yaml"),
+		[]byte("kubeconfigDirs:\n- "+filepath.Join(dir, "*.yaml")+"\n"), 0600))
+
+	// Discovered files follow KUBECONFIG, which wins for duplicates
+	t.Setenv("KUBECONFIG", "/tmp/a"+string(os.PathListSeparator)+filepath.Join(dir, "kind.yaml"))
+	files, err := NewConfigFlags().Files()
+	require.NoError(t, err)
+	assert.Equal(t, []FileSource{
+		{Path: "/tmp/a", Source: SourceEnv},
+		{Path: filepath.Join(dir, "kind.yaml"), Source: SourceEnv},
+		{Path: filepath.Join(dir, "k3d.yaml"), Source: SourceDiscovered, Pattern: filepath.Join(dir, "*.yaml")},
+	}, files)
+
+	rules, err := NewConfigFlags().LoadingRules()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/tmp/a", filepath.Join(dir, "kind.yaml"), filepath.Join(dir, "k3d.yaml")}, rules.GetLoadingPrecedence())
+
+	// The environment variable replaces the config setting
+	t.Setenv(EnvDirs, filepath.Join(dir, "*.txt"))
+	files, err = NewConfigFlags().Files()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "notes.txt"), files[len(files)-1].Path)
+
+	// Disabled files are listed but not loaded
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "kubectl-ctx", "config.yaml"),
+		[]byte("kubeconfigFiles:\n  disabled: [/tmp/a]\n"), 0600))
+	files, err = NewConfigFlags().Files()
+	require.NoError(t, err)
+	assert.True(t, files[0].Disabled)
+	rules, err = NewConfigFlags().LoadingRules()
+	require.NoError(t, err)
+	assert.NotContains(t, rules.GetLoadingPrecedence(), "/tmp/a")
+
+	// --kubeconfig disables discovery
+	flags := NewConfigFlags()
+	flags.KubeConfig = "/tmp/other"
+	files, err = flags.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []FileSource{{Path: "/tmp/other", Source: SourceFlag}}, files)
+}
+
+func TestDiscover_InvalidPattern(t *testing.T) {
+	_, err := Discover([]string{"[invalid"})
+	assert.Error(t, err)
+}
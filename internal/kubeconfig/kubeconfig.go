@@ -0,0 +1,103 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/camaeel/kubectl-ctx/internal/utils/atomicfile"
+	"github.com/camaeel/kubectl-ctx/internal/utils/filelock"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// UpdateOptions hook into the writes of Update
+type UpdateOptions struct {
+	// BeforeWrite, when set, is called under the lock with the files Update
+	// is about to modify. Returning an error aborts the write.
+	BeforeWrite func(files []string) error
+	// DryRun, when set, receives the changes Update would make instead of
+	// Update writing them
+	DryRun func(changes []FileChange) error
+}
+
+// Update locks all kubeconfig files of the loading rules, re-reads the merged
+// configuration, applies mutate and writes the changes back.
+// Re-reading under the lock keeps concurrent changes by other writers.
+// Returns the configuration as written.
+func Update(loadingRules *clientcmd.ClientConfigLoadingRules, options UpdateOptions, mutate func(config *api.Config) error) (*api.Config, error) {
+	unlock, err := filelock.Lock(loadingRules.GetLoadingPrecedence())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	config, err := loadingRules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	original := config.DeepCopy()
+
+	if err := mutate(config); err != nil {
+		return nil, err
+	}
+
+	changes, err := Changes(loadingRules, original, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return config, nil // Nothing to write
+	}
+
+	if options.DryRun != nil {
+		return config, options.DryRun(changes)
+	}
+
+	if options.BeforeWrite != nil {
+		files := make([]string, 0, len(changes))
+		for _, c := range changes {
+			files = append(files, c.Path)
+		}
+		if err := options.BeforeWrite(files); err != nil {
+			return nil, err
+		}
+	}
+
+	// The changes are what ModifyConfig would write, but it locks each file
+	// through the same <file>.lock files we hold, so write them directly
+	if err := writeChanges(changes); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// writeChanges writes the changed files, creating them like
+// clientcmd.WriteToFile does
+func writeChanges(changes []FileChange) error {
+	for _, c := range changes {
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory of %s: %w", c.Path, err)
+		}
+		if err := atomicfile.Write(c.Path, c.After, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// Server returns the server of the cluster a context uses, or "" if the
+// context or its cluster is not defined
+func Server(config *api.Config, contextName string) string {
+	ctx, exists := config.Contexts[contextName]
+	if !exists {
+		return ""
+	}
+	cluster, exists := config.Clusters[ctx.Cluster]
+	if !exists {
+		return ""
+	}
+	return cluster.Server
+}
@@ -0,0 +1,159 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// TestMain keeps the tool config of the user, which ConfigFlags.Files reads,
+// out of the tests
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunIsolated(m))
+}
+
+const helperEnv = "KUBECONFIG_TEST_WRITER_CONTEXT"
+
+func TestUpdate_KeepsConcurrentChanges(t *testing.T) {
+	path := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": "", "ctx2": ""})
+	t.Setenv("KUBECONFIG", path)
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+
+	// Our view is loaded, then another writer changes ctx2 before we write
+	view, err := rules.Load()
+	require.NoError(t, err)
+	other := view.DeepCopy()
+	other.Contexts["ctx2"].Namespace = "other"
+	require.NoError(t, clientcmd.ModifyConfig(rules, *other, false))
+
+	config, err := Update(rules, UpdateOptions{}, func(config *api.Config) error {
+		assert.Equal(t, "other", config.Contexts["ctx2"].Namespace, "Update must re-read under the lock")
+		config.CurrentContext = "ctx2"
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", config.CurrentContext)
+
+	written, err := clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", written.CurrentContext)
+	assert.Equal(t, "other", written.Contexts["ctx2"].Namespace)
+	assert.NoFileExists(t, path+".lock")
+}
+
+func TestUpdate_MutateErrorWritesNothing(t *testing.T) {
+	path := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": ""})
+	t.Setenv("KUBECONFIG", path)
+
+	_, err := Update(clientcmd.NewDefaultClientConfigLoadingRules(), UpdateOptions{}, func(config *api.Config) error {
+		config.CurrentContext = "changed"
+		return fmt.Errorf("boom")
+	})
+	require.Error(t, err)
+
+	written, err := clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ctx1", written.CurrentContext)
+	assert.NoFileExists(t, path+".lock")
+}
+
+// TestUpdate_ConcurrentWriters spawns many processes that each set the
+// namespace of their own context. Without locking and re-reading under the
+// lock, writers would overwrite each other's changes or fail on the lock file.
+func TestUpdate_ConcurrentWriters(t *testing.T) {
+	const writers = 20
+
+	contexts := make(map[string]string, writers)
+	for i := range writers {
+		contexts[fmt.Sprintf("ctx%d", i)] = ""
+	}
+	path := testutil.CreateKubeconfig(t, "ctx0", contexts)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for name := range contexts {
+		wg.Go(func() {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestHelperWriter$")
+			cmd.Env = append(os.Environ(), "KUBECONFIG="+path, helperEnv+"="+name)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("writer %s: %w: %s", name, err, out)
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	written, err := clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	for name := range contexts {
+		assert.Equal(t, "ns-"+name, written.Contexts[name].Namespace, "lost update for %s", name)
+	}
+	assert.NoFileExists(t, path+".lock")
+}
+
+// TestHelperWriter is run as a subprocess by TestUpdate_ConcurrentWriters
+func TestHelperWriter(t *testing.T) {
+	name := os.Getenv(helperEnv)
+	if name == "" {
+		t.Skip("helper process for TestUpdate_ConcurrentWriters")
+	}
+
+	_, err := Update(clientcmd.NewDefaultClientConfigLoadingRules(), UpdateOptions{}, func(config *api.Config) error {
+		config.Contexts[name].Namespace = "ns-" + name
+		config.CurrentContext = name
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUpdate_BeforeWrite(t *testing.T) {
+	path1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": ""})
+	path2 := testutil.CreateKubeconfig(t, "ctx2", map[string]string{"ctx2": ""})
+	t.Setenv("KUBECONFIG", path1+string(os.PathListSeparator)+path2)
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+
+	var written [][]string
+	options := UpdateOptions{BeforeWrite: func(files []string) error {
+		written = append(written, files)
+		return nil
+	}}
+
+	// A no-op does not write anything
+	_, err := Update(rules, options, func(*api.Config) error { return nil })
+	require.NoError(t, err)
+	assert.Empty(t, written)
+
+	// Namespace of ctx2 lives in the second file, current-context in the first
+	_, err = Update(rules, options, func(config *api.Config) error {
+		config.CurrentContext = "ctx2"
+		config.Contexts["ctx2"].Namespace = "web"
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, written, 1)
+	assert.ElementsMatch(t, []string{path1, path2}, written[0])
+
+	// An error from the hook aborts the write
+	options.BeforeWrite = func([]string) error { return fmt.Errorf("denied") }
+	_, err = Update(rules, options, func(config *api.Config) error {
+		config.CurrentContext = "ctx1"
+		return nil
+	})
+	require.Error(t, err)
+
+	config, err := rules.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "ctx2", config.CurrentContext)
+}
@@ -0,0 +1,114 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Kinds of named kubeconfig entries
+const (
+	KindContext = "context"
+	KindCluster = "cluster"
+	KindUser    = "user"
+)
+
+// File is one kubeconfig file of the loading precedence
+type File struct {
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+
+	config *api.Config
+}
+
+// Definition is a named context, cluster or user defined in a file
+type Definition struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	File string `json:"file"`
+	// ShadowedBy is the file whose definition wins the merge; empty for the
+	// effective definition
+	ShadowedBy string `json:"shadowedBy,omitempty"`
+}
+
+// Sources is the per-file view of a merged kubeconfig
+type Sources struct {
+	Files []File
+	// Definitions lists all entries in loading precedence order
+	Definitions []Definition
+	// CurrentContextFile is the file whose current-context wins the merge
+	CurrentContextFile string
+}
+
+// LoadSources reads every file of the loading precedence separately to find
+// out where each entry comes from. Like client-go, the first file to define
+// a name (or current-context) wins.
+func LoadSources(loadingRules *clientcmd.ClientConfigLoadingRules) (*Sources, error) {
+	s := &Sources{}
+	winners := map[string]string{}
+
+	for _, path := range loadingRules.GetLoadingPrecedence() {
+		config, err := clientcmd.LoadFromFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			s.Files = append(s.Files, File{Path: path})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		s.Files = append(s.Files, File{Path: path, Exists: true, config: config})
+
+		if s.CurrentContextFile == "" && config.CurrentContext != "" {
+			s.CurrentContextFile = path
+		}
+
+		add := func(kind string, names []string) {
+			for _, name := range names {
+				d := Definition{Kind: kind, Name: name, File: path}
+				key := kind + "/" + name
+				if winner, ok := winners[key]; ok {
+					d.ShadowedBy = winner
+				} else {
+					winners[key] = path
+				}
+				s.Definitions = append(s.Definitions, d)
+			}
+		}
+		add(KindContext, sortedKeys(config.Contexts))
+		add(KindCluster, sortedKeys(config.Clusters))
+		add(KindUser, sortedKeys(config.AuthInfos))
+	}
+
+	return s, nil
+}
+
+// Lookup returns all definitions of a name, the effective one first
+func (s *Sources) Lookup(kind, name string) []Definition {
+	var defs []Definition
+	for _, d := range s.Definitions {
+		if d.Kind == kind && d.Name == name {
+			defs = append(defs, d)
+		}
+	}
+	return defs
+}
+
+// Shadowed returns the definitions hidden by an earlier file
+func (s *Sources) Shadowed() []Definition {
+	return slices.DeleteFunc(slices.Clone(s.Definitions), func(d Definition) bool { return d.ShadowedBy == "" })
+}
+
+// Config returns the configuration read from a single file, or nil when
+// the file is not part of the loading precedence or does not exist
+func (s *Sources) Config(path string) *api.Config {
+	for _, f := range s.Files {
+		if f.Path == path {
+			return f.config
+		}
+	}
+	return nil
+}
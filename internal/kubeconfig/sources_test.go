@@ -0,0 +1,45 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestLoadSources(t *testing.T) {
+	path1 := testutil.CreateKubeconfig(t, "ctx1", map[string]string{"ctx1": "", "shared": ""})
+	path2 := testutil.CreateKubeconfig(t, "ctx2", map[string]string{"ctx2": "", "shared": ""})
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("KUBECONFIG", path1+string(os.PathListSeparator)+missing+string(os.PathListSeparator)+path2)
+
+	sources, err := LoadSources(clientcmd.NewDefaultClientConfigLoadingRules())
+	require.NoError(t, err)
+
+	require.Len(t, sources.Files, 3)
+	assert.Equal(t, File{Path: missing}, sources.Files[1])
+	assert.True(t, sources.Files[0].Exists)
+	assert.Equal(t, path1, sources.CurrentContextFile)
+
+	assert.Equal(t, []Definition{
+		{Kind: KindContext, Name: "shared", File: path1},
+		{Kind: KindContext, Name: "shared", File: path2, ShadowedBy: path1},
+	}, sources.Lookup(KindContext, "shared"))
+
+	assert.Equal(t, []Definition{{Kind: KindContext, Name: "ctx2", File: path2}}, sources.Lookup(KindContext, "ctx2"))
+
+	// Both files define the same cluster, user and shared context
+	assert.ElementsMatch(t, []Definition{
+		{Kind: KindContext, Name: "shared", File: path2, ShadowedBy: path1},
+		{Kind: KindCluster, Name: "test-cluster", File: path2, ShadowedBy: path1},
+		{Kind: KindUser, Name: "test-user", File: path2, ShadowedBy: path1},
+	}, sources.Shadowed())
+
+	require.NotNil(t, sources.Config(path2))
+	assert.Contains(t, sources.Config(path2).Contexts, "ctx2")
+	assert.Nil(t, sources.Config(missing))
+}
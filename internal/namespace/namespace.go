@@ -3,8 +3,13 @@ package namespace
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -16,22 +21,29 @@ const DefaultNamespace = "default"
 type Manager struct {
 	config         *api.Config
 	loadingRules   *clientcmd.ClientConfigLoadingRules
+	flags          *kubeconfig.ConfigFlags
 	currentContext string
 }
 
-// NewManager creates a new namespace manager
-func NewManager() (*Manager, error) {
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+// NewManager creates a new namespace manager for the kubeconfig selected by
+// flags. The namespace of the --context context is managed if set, otherwise
+// the one of the current context.
+func NewManager(flags *kubeconfig.ConfigFlags) (*Manager, error) {
+	loadingRules, err := flags.LoadingRules()
+	if err != nil {
+		return nil, err
+	}
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, flags.Overrides)
 
 	rawConfig, err := kubeConfig.RawConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
-	currentContext := rawConfig.CurrentContext
+	currentContext := flags.Overrides.CurrentContext
+	if currentContext == "" {
+		currentContext = rawConfig.CurrentContext
+	}
 	if currentContext == "" {
 		return nil, fmt.Errorf("no current context set")
 	}
@@ -40,9 +52,12 @@ func NewManager() (*Manager, error) {
 		return nil, fmt.Errorf("current context %q not found in config", currentContext)
 	}
 
+	slog.Debug("Loaded kubeconfig", "files", loadingRules.GetLoadingPrecedence(), "context", currentContext)
+
 	return &Manager{
 		config:         &rawConfig,
 		loadingRules:   loadingRules,
+		flags:          flags,
 		currentContext: currentContext,
 	}, nil
 }
@@ -61,11 +76,41 @@ func (m *Manager) GetCurrentContext() string {
 	return m.currentContext
 }
 
+// GetServer returns the server of the cluster the current context uses
+func (m *Manager) GetServer() string {
+	return kubeconfig.Server(m.config, m.currentContext)
+}
+
+// ContextFile returns the kubeconfig file that defines the current context,
+// which is where its namespace is written to
+func (m *Manager) ContextFile() string {
+	return m.config.Contexts[m.currentContext].LocationOfOrigin
+}
+
 // ListNamespacesFromCluster fetches namespaces from the cluster
 func (m *Manager) ListNamespacesFromCluster() ([]string, error) {
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	return ListNamespacesForContext(*m.config, m.currentContext, m.flags.ContextOverrides())
+}
+
+// GetNamespacesFromCluster fetches the namespace objects from the cluster
+func (m *Manager) GetNamespacesFromCluster() ([]corev1.Namespace, error) {
+	return GetNamespacesForContext(*m.config, m.currentContext, m.flags.ContextOverrides())
+}
+
+// ListNamespacesForContext fetches namespaces from the cluster of the given
+// context, applying overrides such as --cluster and --user
+func ListNamespacesForContext(config api.Config, contextName string, overrides *clientcmd.ConfigOverrides) ([]string, error) {
+	namespaceList, err := GetNamespacesForContext(config, contextName, overrides)
+	if err != nil {
+		return nil, err
+	}
+	return Names(namespaceList), nil
+}
+
+// GetNamespacesForContext fetches the namespace objects from the cluster of
+// the given context, applying overrides such as --cluster and --user
+func GetNamespacesForContext(config api.Config, contextName string, overrides *clientcmd.ConfigOverrides) ([]corev1.Namespace, error) {
+	kubeConfig := clientcmd.NewNonInteractiveClientConfig(config, contextName, overrides, nil)
 
 	restConfig, err := kubeConfig.ClientConfig()
 	if err != nil {
@@ -83,29 +128,64 @@ func (m *Manager) ListNamespacesFromCluster() ([]string, error) {
 		return nil, err
 	}
 
-	namespaces := make([]string, 0, len(namespaceList.Items))
-	for _, ns := range namespaceList.Items {
-		namespaces = append(namespaces, ns.Name)
+	return namespaceList.Items, nil
+}
+
+// Names returns the names of namespace objects
+func Names(namespaces []corev1.Namespace) []string {
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+	return names
+}
+
+// ValidateNamespace checks that name is a valid namespace name (RFC 1123 label)
+func ValidateNamespace(name string) error {
+	errs := validation.IsDNS1123Label(name)
+	if len(errs) == 0 {
+		return nil
 	}
 
-	return namespaces, nil
+	// Point at the first offending character to make typos easy to spot
+	for i, r := range name {
+		if !isLabelRune(r) {
+			return fmt.Errorf("invalid namespace %q: character %q at position %d is not allowed (%s)",
+				name, r, i, strings.Join(errs, "; "))
+		}
+	}
+
+	return fmt.Errorf("invalid namespace %q: %s", name, strings.Join(errs, "; "))
+}
+
+func isLabelRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-'
 }
 
 // SwitchNamespace switches to the specified namespace
 func (m *Manager) SwitchNamespace(targetNamespace string) error {
+	if err := ValidateNamespace(targetNamespace); err != nil {
+		return err
+	}
+
 	if targetNamespace == m.GetCurrentNamespace() {
 		return nil // Already on target namespace
 	}
 
-	// Update namespace in the current context
-	ctx := m.config.Contexts[m.currentContext]
-	ctx.Namespace = targetNamespace
-	m.config.Contexts[m.currentContext] = ctx
-
-	// Write back the configuration
-	if err := clientcmd.ModifyConfig(m.loadingRules, *m.config, false); err != nil {
+	// Update namespace in the current context and write back the configuration
+	slog.Debug("Writing namespace", "context", m.currentContext, "namespace", targetNamespace, "file", m.ContextFile())
+	config, err := kubeconfig.Update(m.loadingRules, m.flags.Write, func(config *api.Config) error {
+		ctx, exists := config.Contexts[m.currentContext]
+		if !exists {
+			return fmt.Errorf("context %q not found in config", m.currentContext)
+		}
+		ctx.Namespace = targetNamespace
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to switch namespace: %w", err)
 	}
 
+	m.config = config
 	return nil
 }
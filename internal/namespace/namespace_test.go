@@ -3,12 +3,20 @@ package namespace
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/camaeel/kubectl-ctx/internal/kubeconfig"
+	"github.com/camaeel/kubectl-ctx/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// TestMain keeps the tool config and state of the user out of the tests
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunIsolated(m))
+}
+
 func createTestKubeconfig(t *testing.T, currentContext string, contexts map[string]string) string {
 	t.Helper()
 
@@ -74,7 +82,7 @@ func TestNewManager(t *testing.T) {
 			kubeconfigPath := createTestKubeconfig(t, tt.currentContext, tt.contexts)
 			t.Setenv("KUBECONFIG", kubeconfigPath)
 
-			mgr, err := NewManager()
+			mgr, err := NewManager(kubeconfig.NewConfigFlags())
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -118,7 +126,7 @@ func TestGetCurrentNamespace(t *testing.T) {
 			kubeconfigPath := createTestKubeconfig(t, tt.currentContext, tt.contexts)
 			t.Setenv("KUBECONFIG", kubeconfigPath)
 
-			mgr, err := NewManager()
+			mgr, err := NewManager(kubeconfig.NewConfigFlags())
 			require.NoError(t, err)
 
 			ns := mgr.GetCurrentNamespace()
@@ -173,7 +181,7 @@ func TestSwitchNamespace(t *testing.T) {
 			kubeconfigPath := createTestKubeconfig(t, tt.currentContext, tt.contexts)
 			t.Setenv("KUBECONFIG", kubeconfigPath)
 
-			mgr, err := NewManager()
+			mgr, err := NewManager(kubeconfig.NewConfigFlags())
 			require.NoError(t, err)
 
 			err = mgr.SwitchNamespace(tt.targetNamespace)
@@ -190,7 +198,7 @@ func TestSwitchNamespace(t *testing.T) {
 			assert.Equal(t, tt.expectedNamespace, ns)
 
 			// Verify it was persisted by creating a new manager
-			mgr2, err := NewManager()
+			mgr2, err := NewManager(kubeconfig.NewConfigFlags())
 			require.NoError(t, err)
 
 			ns2 := mgr2.GetCurrentNamespace()
@@ -199,13 +207,59 @@ func TestSwitchNamespace(t *testing.T) {
 	}
 }
 
+func TestValidateNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		namespace   string
+		expectError bool
+		errContains string
+	}{
+		{name: "simple name", namespace: "kube-system"},
+		{name: "digits", namespace: "team1-ns2"},
+		{name: "uppercase", namespace: "Payments", expectError: true, errContains: `character 'P' at position 0`},
+		{name: "underscore", namespace: "my_ns", expectError: true, errContains: `character '_' at position 2`},
+		{name: "trailing dash", namespace: "my-ns-", expectError: true, errContains: "must start and end"},
+		{name: "empty", namespace: "", expectError: true},
+		{name: "too long", namespace: strings.Repeat("a", 64), expectError: true, errContains: "63"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNamespace(tt.namespace)
+			if !tt.expectError {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestSwitchNamespace_InvalidNameNotPersisted(t *testing.T) {
+	kubeconfigPath := createTestKubeconfig(t, "test-ctx", map[string]string{
+		"test-ctx": "initial-ns",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	mgr, err := NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+
+	err = mgr.SwitchNamespace("Bad Namespace")
+	require.Error(t, err)
+
+	mgr2, err := NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+	assert.Equal(t, "initial-ns", mgr2.GetCurrentNamespace())
+}
+
 func TestGetCurrentContext(t *testing.T) {
 	kubeconfigPath := createTestKubeconfig(t, "my-context", map[string]string{
 		"my-context": "my-ns",
 	})
 	t.Setenv("KUBECONFIG", kubeconfigPath)
 
-	mgr, err := NewManager()
+	mgr, err := NewManager(kubeconfig.NewConfigFlags())
 	require.NoError(t, err)
 
 	ctx := mgr.GetCurrentContext()
@@ -220,7 +274,7 @@ func TestListNamespacesFromCluster(t *testing.T) {
 	})
 	t.Setenv("KUBECONFIG", kubeconfigPath)
 
-	mgr, err := NewManager()
+	mgr, err := NewManager(kubeconfig.NewConfigFlags())
 	require.NoError(t, err)
 
 	// Attempt to list namespaces from cluster
@@ -238,3 +292,18 @@ func TestListNamespacesFromCluster(t *testing.T) {
 		assert.NotNil(t, namespaces)
 	}
 }
+
+func TestListNamespacesFromCluster_FakeServer(t *testing.T) {
+	server := testutil.NewFakeAPIServer(t, []string{"default", "kube-system"})
+	kubeconfigPath := testutil.CreateKubeconfigWithServer(t, server, "test-ctx", map[string]string{
+		"test-ctx": "default",
+	})
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	mgr, err := NewManager(kubeconfig.NewConfigFlags())
+	require.NoError(t, err)
+
+	namespaces, err := mgr.ListNamespacesFromCluster()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"default", "kube-system"}, namespaces)
+}
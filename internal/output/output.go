@@ -0,0 +1,165 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/credentials"
+)
+
+// Supported output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// SwitchResult describes a completed (or no-op) switch
+type SwitchResult struct {
+	Kind      string `json:"-"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Namespace string `json:"namespace,omitempty"`
+	File      string `json:"file"`
+	Changed   bool   `json:"changed"`
+	DryRun    bool   `json:"dryRun,omitempty"`
+	// Login is the result of pre-warming the credentials with --login
+	Login *credentials.LoginResult `json:"login,omitempty"`
+}
+
+// Printer writes command results (as opposed to diagnostics) to stdout
+type Printer struct {
+	w      io.Writer
+	format string
+}
+
+// New creates a printer for the given format ("" defaults to text)
+func New(w io.Writer, format string) (*Printer, error) {
+	switch format {
+	case "":
+		format = FormatText
+	case FormatText, FormatJSON:
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (use %q or %q)", format, FormatText, FormatJSON)
+	}
+	return &Printer{w: w, format: format}, nil
+}
+
+// List prints one item per line, marking the current item with "* "
+// like `git branch` does, so the output stays easy to parse in scripts
+func (p *Printer) List(items []string, current string) error {
+	if p.format == FormatJSON {
+		return p.json(struct {
+			Current string   `json:"current"`
+			Items   []string `json:"items"`
+		}{Current: current, Items: items})
+	}
+
+	for _, item := range items {
+		marker := "  "
+		if item == current {
+			marker = "* "
+		}
+		if _, err := fmt.Fprintln(p.w, marker+item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Current prints a single value such as the current context name
+func (p *Printer) Current(value string) error {
+	if p.format == FormatJSON {
+		return p.json(struct {
+			Current string `json:"current"`
+		}{Current: value})
+	}
+
+	_, err := fmt.Fprintln(p.w, value)
+	return err
+}
+
+// Switch prints the result of a switch
+func (p *Printer) Switch(r SwitchResult) error {
+	if p.format == FormatJSON {
+		return p.json(r)
+	}
+
+	verb := "Switched"
+	if r.DryRun {
+		verb = "Would switch"
+	}
+
+	var err error
+	switch {
+	case !r.Changed:
+		_, err = fmt.Fprintf(p.w, "Already on %s %q\n", r.Kind, r.To)
+	case r.Namespace != "":
+		_, err = fmt.Fprintf(p.w, "%s to %s %q (namespace %q)\n", verb, r.Kind, r.To, r.Namespace)
+	default:
+		_, err = fmt.Fprintf(p.w, "%s to %s %q\n", verb, r.Kind, r.To)
+	}
+	if err != nil || r.Login == nil {
+		return err
+	}
+	return p.login(r.Login)
+}
+
+// login prints the result of pre-warming credentials
+func (p *Printer) login(l *credentials.LoginResult) error {
+	if l.Method == credentials.MethodStatic {
+		_, err := fmt.Fprintf(p.w, "No credential plugin to run for context %q\n", l.Context)
+		return err
+	}
+
+	details := []string{"server " + l.ServerVersion}
+	if l.Expiry != nil {
+		details = append(details, "expires "+l.Expiry.Local().Format(time.DateTime))
+	}
+	_, err := fmt.Fprintf(p.w, "Logged in to context %q via %s (%s)\n", l.Context, l.Method, strings.Join(details, ", "))
+	return err
+}
+
+// Object prints data as JSON, or renders it as text with the given function
+func (p *Printer) Object(data any, text func(w io.Writer) error) error {
+	if p.format == FormatJSON {
+		return p.json(data)
+	}
+	return text(p.w)
+}
+
+// Diff prints the unified diff of a kubeconfig file
+func (p *Printer) Diff(file, diff string) error {
+	if p.format == FormatJSON {
+		return p.json(struct {
+			File string `json:"file"`
+			Diff string `json:"diff"`
+		}{File: file, Diff: diff})
+	}
+
+	_, err := io.WriteString(p.w, diff)
+	return err
+}
+
+// Table prints rows under an upper-cased header; JSON output encodes data instead
+func (p *Printer) Table(header []string, rows [][]string, data any) error {
+	if p.format == FormatJSON {
+		return p.json(data)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *Printer) json(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,132 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_UnsupportedFormat(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "yaml")
+	assert.Error(t, err)
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{name: "text", format: "", want: "  dev\n* prod\n  staging\n"},
+		{name: "json", format: FormatJSON, want: "{\n  \"current\": \"prod\",\n  \"items\": [\n    \"dev\",\n    \"prod\",\n    \"staging\"\n  ]\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p, err := New(&buf, tt.format)
+			require.NoError(t, err)
+			require.NoError(t, p.List([]string{"dev", "prod", "staging"}, "prod"))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	var buf bytes.Buffer
+	p, err := New(&buf, FormatText)
+	require.NoError(t, err)
+	require.NoError(t, p.Current("prod"))
+	assert.Equal(t, "prod\n", buf.String())
+}
+
+func TestSwitch(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		result SwitchResult
+		want   string
+	}{
+		{
+			name:   "text switched",
+			result: SwitchResult{Kind: "context", From: "dev", To: "prod", File: "/kube/config", Changed: true},
+			want:   "Switched to context \"prod\"\n",
+		},
+		{
+			name:   "text switched with namespace",
+			result: SwitchResult{Kind: "context", From: "dev", To: "prod", Namespace: "payments", File: "/kube/config", Changed: true},
+			want:   "Switched to context \"prod\" (namespace \"payments\")\n",
+		},
+		{
+			name:   "text unchanged",
+			result: SwitchResult{Kind: "namespace", From: "dev", To: "dev"},
+			want:   "Already on namespace \"dev\"\n",
+		},
+		{
+			name: "text with login",
+			result: SwitchResult{Kind: "context", From: "dev", To: "prod", Changed: true,
+				Login: &credentials.LoginResult{Context: "prod", Method: credentials.MethodExec, ServerVersion: "v1.30.0"}},
+			want: "Switched to context \"prod\"\nLogged in to context \"prod\" via exec (server v1.30.0)\n",
+		},
+		{
+			name:   "text with static credentials",
+			result: SwitchResult{Kind: "context", From: "dev", To: "dev", Login: &credentials.LoginResult{Context: "dev", Method: credentials.MethodStatic}},
+			want:   "Already on context \"dev\"\nNo credential plugin to run for context \"dev\"\n",
+		},
+		{
+			name:   "json",
+			format: FormatJSON,
+			result: SwitchResult{Kind: "context", From: "dev", To: "prod", File: "/kube/config", Changed: true},
+			want:   "{\n  \"from\": \"dev\",\n  \"to\": \"prod\",\n  \"file\": \"/kube/config\",\n  \"changed\": true\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p, err := New(&buf, tt.format)
+			require.NoError(t, err)
+			require.NoError(t, p.Switch(tt.result))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestTable(t *testing.T) {
+	rows := [][]string{{"a", "first"}, {"bbbb", "second"}}
+
+	var buf bytes.Buffer
+	p, err := New(&buf, FormatText)
+	require.NoError(t, err)
+	require.NoError(t, p.Table([]string{"id", "value"}, rows, rows))
+	assert.Equal(t, "ID     VALUE\na      first\nbbbb   second\n", buf.String())
+
+	buf.Reset()
+	p, err = New(&buf, FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, p.Table([]string{"id", "value"}, rows, []string{"data"}))
+	assert.Equal(t, "[\n  \"data\"\n]\n", buf.String())
+}
+
+func TestObject(t *testing.T) {
+	text := func(w io.Writer) error {
+		_, err := io.WriteString(w, "rendered\n")
+		return err
+	}
+
+	var buf bytes.Buffer
+	p, err := New(&buf, FormatText)
+	require.NoError(t, err)
+	require.NoError(t, p.Object(map[string]int{"a": 1}, text))
+	assert.Equal(t, "rendered\n", buf.String())
+
+	buf.Reset()
+	p, err = New(&buf, FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, p.Object(map[string]int{"a": 1}, text))
+	assert.Equal(t, "{\n  \"a\": 1\n}\n", buf.String())
+}
@@ -0,0 +1,83 @@
+package picker
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// match is an item matching the filter, with the rune positions of the
+// matched characters
+type match struct {
+	item      string
+	index     int
+	score     int
+	positions []int
+}
+
+// filter returns the items fuzzy matching pattern, best matches first. Ties
+// go to the earlier match, then the shorter item. An empty pattern matches
+// all items in their order.
+func filter(items []string, pattern string) []match {
+	matches := make([]match, 0, len(items))
+	for i, item := range items {
+		if score, positions, ok := fuzzyMatch(pattern, item); ok {
+			matches = append(matches, match{item: item, index: i, score: score, positions: positions})
+		}
+	}
+	if pattern == "" {
+		return matches
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		if a.positions[0] != b.positions[0] {
+			return a.positions[0] - b.positions[0]
+		}
+		return len(a.item) - len(b.item)
+	})
+	return matches
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in s in order,
+// ignoring case. Consecutive matches and matches at the start of words
+// (after - _ . / : or a space) score higher, gaps score lower.
+func fuzzyMatch(pattern, s string) (score int, positions []int, ok bool) {
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	runes := []rune(strings.ToLower(s))
+	j := 0
+	for i, r := range runes {
+		if j == len(p) {
+			break
+		}
+		if r != p[j] {
+			continue
+		}
+
+		switch {
+		case i == 0 || isSeparator(runes[i-1]):
+			score += 3
+		case len(positions) > 0 && positions[len(positions)-1] == i-1:
+			score += 5
+		}
+		if len(positions) > 0 {
+			score -= i - positions[len(positions)-1] - 1
+		}
+		positions = append(positions, i)
+		j++
+	}
+
+	if j < len(p) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("-_./:@", r)
+}
@@ -0,0 +1,581 @@
+// Package picker implements the full-screen interactive selector: a fuzzy
+// filtered list with a preview pane and keybindings for acting on items
+package picker
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrCanceled is returned when the picker is closed without a selection
+var ErrCanceled = errors.New("selection canceled")
+
+// refreshInterval is how often the picker redraws to show item
+// descriptions as they become available
+const refreshInterval = 250 * time.Millisecond
+
+// Options configure a picker
+type Options struct {
+	// Kind names the items in prompts, e.g. "context"
+	Kind string
+	// Items are listed in this order while no filter is entered
+	Items []string
+	// Current is marked and initially highlighted
+	Current string
+	// Pinned items are listed first, above a separator, also while filtering
+	Pinned []string
+	// Preview renders the preview pane for the highlighted item. The pane
+	// is hidden when nil.
+	Preview func(item string) string
+	// Describe returns a short annotation shown next to an item. It is
+	// polled while the picker is open, so it may return data that is still
+	// being fetched.
+	Describe func(item string) string
+	// Rename renames an item; renaming is disabled when nil
+	Rename func(oldName, newName string) error
+	// Delete deletes an item; deleting is disabled when nil
+	Delete func(item string) error
+	// Clipboard receives the OSC 52 sequence copying a name to the
+	// terminal's clipboard
+	Clipboard io.Writer
+	// Color enables ANSI styling
+	Color bool
+	// Style returns the ANSI sequence coloring the name of an item, or ""
+	Style func(item string) string
+	// PageSize limits the number of listed rows; 0 fills the terminal
+	PageSize int
+}
+
+// ordered returns the items with the pinned ones first, in the order they
+// are pinned, and the number of pinned items
+func (o Options) ordered() ([]string, int) {
+	items := make([]string, 0, len(o.Items))
+	for _, item := range o.Pinned {
+		if slices.Contains(o.Items, item) && !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	pinned := len(items)
+	for _, item := range o.Items {
+		if !slices.Contains(items[:pinned], item) {
+			items = append(items, item)
+		}
+	}
+	return items, pinned
+}
+
+// Run shows the picker full-screen on stderr, keeping stdout for results,
+// and returns the selected item
+func Run(opts Options) (string, error) {
+	if opts.Clipboard == nil {
+		opts.Clipboard = os.Stderr
+	}
+
+	program := tea.NewProgram(newModel(opts), tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+	final, err := program.Run()
+	if err != nil {
+		return "", err
+	}
+
+	m := final.(*model)
+	if m.selected == "" {
+		return "", ErrCanceled
+	}
+	return m.selected, nil
+}
+
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeRename
+	modeConfirmDelete
+)
+
+type tickMsg struct{}
+
+// model is the bubbletea model of the picker
+type model struct {
+	opts    Options
+	items   []string
+	filter  string
+	matches []match
+	// pinned is the number of leading matches that are pinned
+	pinned int
+	cursor int
+	offset int
+	width  int
+	height int
+
+	mode   mode
+	input  string
+	status string
+
+	selected string
+	done     bool
+}
+
+func newModel(opts Options) *model {
+	items, _ := opts.ordered()
+	opts.Pinned = slices.Clone(opts.Pinned)
+	m := &model{opts: opts, items: items, width: 80, height: 24}
+	m.refilter()
+	m.moveTo(opts.Current)
+	return m
+}
+
+func (m *model) Init() tea.Cmd {
+	if m.opts.Describe == nil {
+		return nil
+	}
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Terminals that don't report their size keep the default
+		if msg.Width > 0 && msg.Height > 0 {
+			m.width, m.height = msg.Width, msg.Height
+			m.scroll()
+		}
+	case tickMsg:
+		if !m.done {
+			return m, tick()
+		}
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m.quit("")
+		}
+		switch m.mode {
+		case modeRename:
+			return m.updateRename(msg)
+		case modeConfirmDelete:
+			return m.updateConfirmDelete(msg)
+		default:
+			return m.updateBrowse(msg)
+		}
+	}
+	return m, nil
+}
+
+func (m *model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	item, ok := m.highlighted()
+
+	switch msg.String() {
+	case "enter":
+		if ok {
+			return m.quit(item)
+		}
+	case "esc":
+		if m.filter == "" {
+			return m.quit("")
+		}
+		m.setFilter("")
+	case "up", "ctrl+p", "ctrl+k":
+		m.move(-1)
+	case "down", "ctrl+n", "ctrl+j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.listHeight())
+	case "pgdown":
+		m.move(m.listHeight())
+	case "home":
+		m.move(-len(m.matches))
+	case "end":
+		m.move(len(m.matches))
+	case "backspace":
+		if r := []rune(m.filter); len(r) > 0 {
+			m.setFilter(string(r[:len(r)-1]))
+		}
+	case "ctrl+r":
+		if ok && m.opts.Rename != nil {
+			m.mode, m.input = modeRename, item
+		}
+	case "ctrl+x":
+		if ok && m.opts.Delete != nil {
+			m.mode = modeConfirmDelete
+		}
+	case "ctrl+y":
+		if ok {
+			m.copy(item)
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			m.setFilter(m.filter + string(msg.Runes))
+		}
+	}
+	return m, nil
+}
+
+func (m *model) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		oldName, _ := m.highlighted()
+		m.mode = modeBrowse
+		if m.input == "" || m.input == oldName {
+			return m, nil
+		}
+		if err := m.opts.Rename(oldName, m.input); err != nil {
+			m.status = "Error: " + err.Error()
+			return m, nil
+		}
+		m.items[slices.Index(m.items, oldName)] = m.input
+		if m.opts.Current == oldName {
+			m.opts.Current = m.input
+		}
+		if i := slices.Index(m.opts.Pinned, oldName); i >= 0 {
+			m.opts.Pinned[i] = m.input
+		}
+		m.status = fmt.Sprintf("Renamed %s %q to %q", m.opts.Kind, oldName, m.input)
+		m.refilter()
+		m.moveTo(m.input)
+	case "esc":
+		m.mode = modeBrowse
+	case "backspace":
+		if r := []rune(m.input); len(r) > 0 {
+			m.input = string(r[:len(r)-1])
+		}
+	default:
+		if msg.Type == tea.KeyRunes {
+			m.input += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+func (m *model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.mode = modeBrowse
+	if msg.String() != "y" {
+		return m, nil
+	}
+
+	item, _ := m.highlighted()
+	if err := m.opts.Delete(item); err != nil {
+		m.status = "Error: " + err.Error()
+		return m, nil
+	}
+	m.items = slices.DeleteFunc(m.items, func(i string) bool { return i == item })
+	m.status = fmt.Sprintf("Deleted %s %q", m.opts.Kind, item)
+	m.refilter()
+	m.move(0)
+	return m, nil
+}
+
+// copy puts name on the terminal's clipboard with OSC 52, which works over
+// SSH and in tmux without clipboard tools
+func (m *model) copy(name string) {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(name)) + "\a"
+	if _, err := io.WriteString(m.opts.Clipboard, seq); err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	m.status = fmt.Sprintf("Copied %q", name)
+}
+
+func (m *model) quit(selected string) (tea.Model, tea.Cmd) {
+	m.selected, m.done = selected, true
+	return m, tea.Quit
+}
+
+func (m *model) highlighted() (string, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.matches) {
+		return "", false
+	}
+	return m.matches[m.cursor].item, true
+}
+
+func (m *model) setFilter(filter string) {
+	m.filter = filter
+	m.refilter()
+	m.cursor, m.offset = 0, 0
+}
+
+// refilter matches the items against the filter, keeping pinned matches
+// first
+func (m *model) refilter() {
+	matches := filter(m.items, m.filter)
+	pinned := slices.DeleteFunc(slices.Clone(matches), func(match match) bool { return !m.isPinned(match.item) })
+	others := slices.DeleteFunc(matches, func(match match) bool { return m.isPinned(match.item) })
+	m.matches = append(pinned, others...)
+	m.pinned = len(pinned)
+}
+
+func (m *model) isPinned(item string) bool {
+	return slices.Contains(m.opts.Pinned, item)
+}
+
+// separated reports whether a separator is drawn between the pinned and
+// the other matches
+func (m *model) separated() bool {
+	return m.pinned > 0 && m.pinned < len(m.matches)
+}
+
+// rowOf returns the list row showing match i
+func (m *model) rowOf(i int) int {
+	if m.separated() && i >= m.pinned {
+		return i + 1
+	}
+	return i
+}
+
+// rows is the number of list rows, including the separator
+func (m *model) rows() int {
+	return m.rowOf(len(m.matches))
+}
+
+func (m *model) moveTo(item string) {
+	for i, match := range m.matches {
+		if match.item == item {
+			m.cursor = i
+			break
+		}
+	}
+	m.scroll()
+}
+
+func (m *model) move(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.matches)-1))
+	m.scroll()
+}
+
+// scroll keeps the cursor within the visible part of the list
+func (m *model) scroll() {
+	height := m.listHeight()
+	row := m.rowOf(m.cursor)
+	if row < m.offset {
+		m.offset = row
+	}
+	if row >= m.offset+height {
+		m.offset = row - height + 1
+	}
+	m.offset = max(0, min(m.offset, m.rows()-height))
+}
+
+// listHeight is the number of rows between the header and the footer
+func (m *model) listHeight() int {
+	height := max(1, m.height-2)
+	if m.opts.PageSize > 0 {
+		height = min(height, m.opts.PageSize)
+	}
+	return height
+}
+
+func (m *model) View() string {
+	if m.done {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(m.header())
+	sb.WriteString("\n")
+
+	listWidth := m.width
+	var preview []string
+	if m.opts.Preview != nil {
+		listWidth = max(20, m.width*2/5)
+		if item, ok := m.highlighted(); ok {
+			preview = strings.Split(strings.TrimRight(m.opts.Preview(item), "\n"), "\n")
+		}
+	}
+	previewWidth := m.width - listWidth - 3
+
+	for row := range m.listHeight() {
+		line := pad(m.line(m.offset+row, listWidth), listWidth)
+		if previewWidth > 0 {
+			previewLine := ""
+			if row < len(preview) {
+				previewLine = truncate(preview[row], previewWidth)
+			}
+			line += " " + m.style(dim, "│") + " " + previewLine
+		}
+		sb.WriteString(strings.TrimRight(line, " "))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(m.footer())
+	return sb.String()
+}
+
+func (m *model) header() string {
+	item, _ := m.highlighted()
+	var prompt string
+	switch m.mode {
+	case modeRename:
+		prompt = fmt.Sprintf("Rename %s %q to: %s█", m.opts.Kind, item, m.input)
+	case modeConfirmDelete:
+		prompt = fmt.Sprintf("Delete %s %q? (y/N)", m.opts.Kind, item)
+	default:
+		prompt = fmt.Sprintf("Select %s > %s█", m.opts.Kind, m.filter)
+	}
+
+	count := strconv.Itoa(len(m.matches)) + "/" + strconv.Itoa(len(m.items))
+	gap := m.width - runeLen(prompt) - runeLen(count)
+	if gap < 1 {
+		return truncate(prompt, m.width)
+	}
+	return m.style(bold, prompt) + strings.Repeat(" ", gap) + m.style(dim, count)
+}
+
+func (m *model) footer() string {
+	if m.status != "" {
+		return truncate(m.status, m.width)
+	}
+
+	help := []string{"↑/↓ move", "enter select"}
+	if m.opts.Rename != nil {
+		help = append(help, "ctrl-r rename")
+	}
+	if m.opts.Delete != nil {
+		help = append(help, "ctrl-x delete")
+	}
+	help = append(help, "ctrl-y copy", "esc quit")
+	return m.style(dim, truncate(strings.Join(help, " · "), m.width))
+}
+
+// line renders list row i, which is a match or the separator below the
+// pinned matches
+func (m *model) line(i, width int) string {
+	if m.separated() {
+		switch {
+		case i == m.pinned:
+			return m.style(dim, strings.Repeat("─", width))
+		case i > m.pinned:
+			i--
+		}
+	}
+	return m.row(i, width)
+}
+
+// row renders the list entry at index i of the matches
+func (m *model) row(i, width int) string {
+	if i >= len(m.matches) {
+		return ""
+	}
+	match := m.matches[i]
+
+	cursor, current := "  ", "  "
+	if i == m.cursor {
+		cursor = "▸ "
+	}
+	if match.item == m.opts.Current {
+		current = "* "
+	}
+
+	name := match.item
+	base := m.itemStyle(match.item)
+	switch {
+	case runeLen(name) > width-4:
+		name = m.style(base, truncate(name, width-4))
+	case m.opts.Color && len(match.positions) > 0:
+		name = m.highlight(match, base)
+	default:
+		name = m.style(base, name)
+	}
+	line := cursor + current + name
+
+	if m.opts.Describe != nil {
+		if desc := m.opts.Describe(match.item); desc != "" {
+			room := width - 4 - runeLen(match.item) - 2
+			if room > 3 {
+				line += "  " + m.style(dim, truncate(desc, room))
+			}
+		}
+	}
+	if i == m.cursor {
+		line = m.style(bold, line)
+	}
+	return line
+}
+
+// highlight underlines the characters matched by the filter, restoring the
+// base style of the item after each
+func (m *model) highlight(match match, base string) string {
+	var sb strings.Builder
+	sb.WriteString(base)
+	for i, r := range []rune(match.item) {
+		if slices.Contains(match.positions, i) {
+			sb.WriteString(m.style(underline, string(r)))
+			sb.WriteString(base)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	if base != "" {
+		sb.WriteString(reset)
+	}
+	return sb.String()
+}
+
+// itemStyle returns the style of an item from Options.Style, if enabled
+func (m *model) itemStyle(item string) string {
+	if !m.opts.Color || m.opts.Style == nil {
+		return ""
+	}
+	return m.opts.Style(item)
+}
+
+const (
+	bold      = "\033[1m"
+	dim       = "\033[90m"
+	underline = "\033[4m"
+	reset     = "\033[0m"
+)
+
+func (m *model) style(sgr, s string) string {
+	if !m.opts.Color || sgr == "" || s == "" {
+		return s
+	}
+	return sgr + s + reset
+}
+
+// pad fills s with spaces to width visible characters
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-visibleLen(s)))
+}
+
+// truncate shortens s to width runes, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(r[:max(0, width)])
+	}
+	return string(r[:width-1]) + "…"
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
+
+// visibleLen counts the runes of s outside of ANSI escape sequences
+func visibleLen(s string) int {
+	n, escape := 0, false
+	for _, r := range s {
+		switch {
+		case escape:
+			escape = r != 'm'
+		case r == '\033':
+			escape = true
+		default:
+			n++
+		}
+	}
+	return n
+}
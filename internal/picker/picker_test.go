@@ -0,0 +1,303 @@
+package picker
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// Simulated special keys
+var (
+	keyUp        = tea.KeyMsg{Type: tea.KeyUp}
+	keyDown      = tea.KeyMsg{Type: tea.KeyDown}
+	keyEnter     = tea.KeyMsg{Type: tea.KeyEnter}
+	keyEsc       = tea.KeyMsg{Type: tea.KeyEsc}
+	keyBackspace = tea.KeyMsg{Type: tea.KeyBackspace}
+	keyCtrlC     = tea.KeyMsg{Type: tea.KeyCtrlC}
+	keyCtrlR     = tea.KeyMsg{Type: tea.KeyCtrlR}
+	keyCtrlX     = tea.KeyMsg{Type: tea.KeyCtrlX}
+	keyCtrlY     = tea.KeyMsg{Type: tea.KeyCtrlY}
+)
+
+// typed returns the key messages of typing s
+func typed(s string) []tea.Msg {
+	msgs := make([]tea.Msg, 0, len(s))
+	for _, r := range s {
+		msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return msgs
+}
+
+// keys flattens key messages and typed strings into one sequence
+func keys(input ...any) []tea.Msg {
+	var msgs []tea.Msg
+	for _, in := range input {
+		switch in := in.(type) {
+		case string:
+			msgs = append(msgs, typed(in)...)
+		case tea.Msg:
+			msgs = append(msgs, in)
+		}
+	}
+	return msgs
+}
+
+// drive runs a picker on an 80x12 screen through the key sequence without a
+// terminal and returns the final model
+func drive(t *testing.T, opts Options, msgs []tea.Msg) *model {
+	t.Helper()
+
+	m := newModel(opts)
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 12})
+	for _, msg := range msgs {
+		m.Update(msg)
+	}
+	return m
+}
+
+// assertGolden compares the screen with testdata/NAME.golden, rewriting
+// the file with -update
+func assertGolden(t *testing.T, name, screen string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, []byte(screen), 0644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run go test -update to create the golden file")
+	assert.Equal(t, string(want), screen)
+}
+
+var contexts = []string{"dev", "kind-kind", "prod-eu", "prod-us", "staging"}
+
+func preview(item string) string {
+	return "Context:  " + item + "\n  Server:  https://" + item + ".example.com:6443\n"
+}
+
+func TestPicker_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		keys []tea.Msg
+	}{
+		{
+			name: "initial",
+			opts: Options{Kind: "context", Items: contexts, Current: "prod-eu", Preview: preview},
+		},
+		{
+			name: "filter",
+			opts: Options{Kind: "context", Items: contexts, Current: "dev", Preview: preview},
+			keys: keys("prd", keyDown),
+		},
+		{
+			name: "no_match",
+			opts: Options{Kind: "context", Items: contexts, Preview: preview},
+			keys: keys("xyz"),
+		},
+		{
+			name: "describe",
+			opts: Options{Kind: "context", Items: contexts, Current: "dev", Describe: func(item string) string {
+				if item == "staging" {
+					return "loading..."
+				}
+				return "v1.30.2, 3 nodes"
+			}},
+		},
+		{
+			name: "rename_prompt",
+			opts: Options{Kind: "context", Items: contexts, Current: "dev", Preview: preview,
+				Rename: func(string, string) error { return nil }, Delete: func(string) error { return nil }},
+			keys: keys(keyDown, keyCtrlR, keyBackspace, keyBackspace, keyBackspace, keyBackspace, "local"),
+		},
+		{
+			name: "delete_prompt",
+			opts: Options{Kind: "context", Items: contexts, Current: "dev", Preview: preview,
+				Rename: func(string, string) error { return nil }, Delete: func(string) error { return nil }},
+			keys: keys(keyDown, keyCtrlX),
+		},
+		{
+			name: "pinned",
+			opts: Options{Kind: "context", Items: contexts, Current: "dev", Pinned: []string{"staging", "prod-eu"}, Preview: preview},
+		},
+		{
+			name: "pinned_filter",
+			opts: Options{Kind: "context", Items: contexts, Current: "dev", Pinned: []string{"staging", "prod-eu"}, Preview: preview},
+			keys: keys("e", keyDown, keyDown),
+		},
+		{
+			name: "pinned_scrolled",
+			opts: Options{Kind: "namespace", Items: namespaces(20), Current: "ns-00", Pinned: []string{"ns-15", "ns-07"}},
+			keys: keys(keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown),
+		},
+		{
+			name: "scrolled",
+			opts: Options{Kind: "namespace", Items: namespaces(20), Current: "ns-00"},
+			keys: keys(keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown, keyDown),
+		},
+		{
+			name: "page_size",
+			opts: Options{Kind: "namespace", Items: namespaces(20), Current: "ns-00", PageSize: 4},
+			keys: keys(keyDown, keyDown, keyDown, keyDown, keyDown),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := drive(t, tt.opts, tt.keys)
+			assertGolden(t, tt.name, m.View())
+		})
+	}
+}
+
+func namespaces(n int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = "ns-" + string(rune('0'+i/10)) + string(rune('0'+i%10))
+	}
+	return items
+}
+
+func TestPicker_Select(t *testing.T) {
+	m := drive(t, Options{Kind: "context", Items: contexts, Current: "dev"}, keys(keyDown, keyDown, keyUp, keyEnter))
+	assert.Equal(t, "kind-kind", m.selected)
+	assert.Empty(t, m.View())
+
+	m = drive(t, Options{Kind: "context", Items: contexts}, keys("stg", keyEnter))
+	assert.Equal(t, "staging", m.selected)
+
+	// Enter without matches keeps the picker open
+	m = drive(t, Options{Kind: "context", Items: contexts}, keys("xyz", keyEnter))
+	assert.False(t, m.done)
+}
+
+func TestPicker_Cancel(t *testing.T) {
+	// Esc clears the filter first
+	m := drive(t, Options{Kind: "context", Items: contexts}, keys("prod", keyEsc))
+	assert.False(t, m.done)
+	assert.Empty(t, m.filter)
+
+	m = drive(t, Options{Kind: "context", Items: contexts}, keys(keyEsc))
+	assert.True(t, m.done)
+	assert.Empty(t, m.selected)
+
+	m = drive(t, Options{Kind: "context", Items: contexts}, keys("prod", keyCtrlC))
+	assert.True(t, m.done)
+	assert.Empty(t, m.selected)
+}
+
+func TestPicker_Rename(t *testing.T) {
+	var renamed []string
+	opts := Options{Kind: "context", Items: contexts, Current: "dev", Rename: func(oldName, newName string) error {
+		if newName == "prod-us" {
+			return errors.New("context \"prod-us\" already exists")
+		}
+		renamed = append(renamed, oldName+"->"+newName)
+		return nil
+	}}
+
+	m := drive(t, opts, keys(keyCtrlR, "-old", keyEnter))
+	assert.Equal(t, []string{"dev->dev-old"}, renamed)
+	assert.Equal(t, "dev-old", m.opts.Current)
+	assert.Contains(t, m.items, "dev-old")
+	assert.Equal(t, `Renamed context "dev" to "dev-old"`, m.status)
+	item, _ := m.highlighted()
+	assert.Equal(t, "dev-old", item)
+
+	m = drive(t, opts, keys(keyCtrlR, keyBackspace, keyBackspace, keyBackspace, "prod-us", keyEnter))
+	assert.Contains(t, m.status, "already exists")
+	assert.Contains(t, m.items, "dev")
+
+	// Esc leaves the items alone
+	renamed = nil
+	drive(t, opts, keys(keyCtrlR, "x", keyEsc))
+	assert.Empty(t, renamed)
+}
+
+func TestPicker_Delete(t *testing.T) {
+	var deleted []string
+	opts := Options{Kind: "context", Items: contexts, Current: "dev", Delete: func(item string) error {
+		deleted = append(deleted, item)
+		return nil
+	}}
+
+	m := drive(t, opts, keys(keyDown, keyCtrlX, "n"))
+	assert.Empty(t, deleted)
+	assert.Len(t, m.items, len(contexts))
+
+	m = drive(t, opts, keys(keyDown, keyCtrlX, "y"))
+	assert.Equal(t, []string{"kind-kind"}, deleted)
+	assert.NotContains(t, m.items, "kind-kind")
+	item, _ := m.highlighted()
+	assert.Equal(t, "prod-eu", item)
+
+	// Without a Delete function the key does nothing
+	m = drive(t, Options{Kind: "namespace", Items: contexts}, keys(keyCtrlX))
+	assert.Equal(t, modeBrowse, m.mode)
+}
+
+func TestPicker_Copy(t *testing.T) {
+	var clipboard bytes.Buffer
+	m := drive(t, Options{Kind: "context", Items: contexts, Current: "dev", Clipboard: &clipboard}, keys(keyCtrlY))
+	assert.Equal(t, "\x1b]52;c;ZGV2\a", clipboard.String())
+	assert.Equal(t, `Copied "dev"`, m.status)
+
+	// The status is cleared by the next key
+	m.Update(keyDown)
+	assert.Empty(t, m.status)
+}
+
+func TestPicker_Color(t *testing.T) {
+	m := drive(t, Options{Kind: "context", Items: contexts, Color: true}, keys("prd"))
+	assert.Contains(t, m.View(), "\033[4mp\033[0m")
+
+	for _, line := range strings.Split(m.View(), "\n") {
+		assert.LessOrEqual(t, visibleLen(line), 80)
+	}
+}
+
+func TestPicker_Style(t *testing.T) {
+	red := "\033[31m"
+	style := func(item string) string {
+		if strings.HasPrefix(item, "prod-") {
+			return red
+		}
+		return ""
+	}
+
+	m := drive(t, Options{Kind: "context", Items: contexts, Color: true, Style: style}, nil)
+	assert.Contains(t, m.View(), red+"prod-eu"+reset)
+	assert.NotContains(t, m.View(), red+"dev")
+
+	// Matched characters are underlined without losing the color
+	m = drive(t, Options{Kind: "context", Items: contexts, Color: true, Style: style}, keys("eu"))
+	assert.Contains(t, m.View(), red+"prod-"+underline+"e"+reset+red+underline+"u"+reset+red+reset)
+
+	m = drive(t, Options{Kind: "context", Items: contexts, Style: style}, nil)
+	assert.NotContains(t, m.View(), red)
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	_, positions, ok := fuzzyMatch("pe", "prod-eu")
+	assert.True(t, ok)
+	assert.Equal(t, []int{0, 5}, positions)
+
+	_, _, ok = fuzzyMatch("ep", "prod-eu")
+	assert.False(t, ok)
+
+	// Word starts and consecutive characters rank first
+	matches := filter([]string{"sandbox-eu", "staging-eu", "eu-west"}, "eu")
+	assert.Equal(t, "eu-west", matches[0].item)
+}
@@ -0,0 +1,121 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Plain prompts for an item with a numbered list on out, for screen readers
+// and terminals without cursor control. Each line read from in is an item
+// number, a name or a name prefix matching a single item; invalid input is
+// prompted for again. An empty line keeps the current item.
+func Plain(opts Options, in io.Reader, out io.Writer) (string, error) {
+	if len(opts.Items) == 0 {
+		return "", fmt.Errorf("no %ss to select from", opts.Kind)
+	}
+
+	items, pinned := opts.ordered()
+	opts.Items = items
+
+	width := len(strconv.Itoa(len(items)))
+	for i, item := range items {
+		if i == pinned && pinned > 0 {
+			_, _ = fmt.Fprintln(out, strings.Repeat("-", width+2))
+		}
+		line := fmt.Sprintf("%*d) %s", width, i+1, item)
+		if item == opts.Current {
+			line += " (current)"
+		}
+		if opts.Describe != nil {
+			if description := opts.Describe(item); description != "" {
+				line += " - " + description
+			}
+		}
+		_, _ = fmt.Fprintln(out, line)
+	}
+
+	prompt := fmt.Sprintf("Select %s (number, name or prefix)", opts.Kind)
+	if opts.Current != "" {
+		prompt += " [" + opts.Current + "]"
+	}
+
+	for {
+		_, _ = fmt.Fprint(out, prompt+": ")
+		answer, err := readLine(in)
+		if err != nil {
+			_, _ = fmt.Fprintln(out)
+			if errors.Is(err, io.EOF) {
+				return "", ErrCanceled
+			}
+			return "", err
+		}
+
+		item, err := resolve(opts, strings.TrimSpace(answer))
+		if err == nil {
+			return item, nil
+		}
+		_, _ = fmt.Fprintln(out, err)
+	}
+}
+
+// readLine reads a line without reading ahead, so later prompts can read
+// the following lines from the same input
+func readLine(in io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := in.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			line = append(line, b[0])
+		}
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// resolve returns the item an answer to the plain prompt selects
+func resolve(opts Options, answer string) (string, error) {
+	if answer == "" {
+		if opts.Current == "" {
+			return "", fmt.Errorf("enter a %s number, name or prefix", opts.Kind)
+		}
+		return opts.Current, nil
+	}
+
+	// Names take precedence, so items named like numbers stay selectable
+	var prefixed []string
+	for _, item := range opts.Items {
+		if item == answer {
+			return item, nil
+		}
+		if strings.HasPrefix(item, answer) {
+			prefixed = append(prefixed, item)
+		}
+	}
+
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n < 1 || n > len(opts.Items) {
+			return "", fmt.Errorf("no %s number %d, enter 1-%d", opts.Kind, n, len(opts.Items))
+		}
+		return opts.Items[n-1], nil
+	}
+
+	switch len(prefixed) {
+	case 0:
+		return "", fmt.Errorf("no %s matches %q", opts.Kind, answer)
+	case 1:
+		return prefixed[0], nil
+	default:
+		return "", fmt.Errorf("%q matches several %ss: %s", answer, opts.Kind, strings.Join(prefixed, ", "))
+	}
+}
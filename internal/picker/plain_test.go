@@ -0,0 +1,85 @@
+package picker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlain(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "number", input: "2\n", want: "kind-kind"},
+		{name: "name", input: "prod-us\n", want: "prod-us"},
+		{name: "unique prefix", input: "st\n", want: "staging"},
+		{name: "empty keeps current", current: "prod-eu", input: "\n", want: "prod-eu"},
+		{name: "reprompt after invalid input", input: "\nprod\n9\nxyz\n 1 \n", want: "dev"},
+		{name: "end of input", input: "xyz\n", wantErr: ErrCanceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := Options{Kind: "context", Items: contexts, Current: tt.current}
+			got, err := Plain(opts, strings.NewReader(tt.input), &out)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPlain_Output(t *testing.T) {
+	items := append(namespaces(8), "10", "prod")
+	opts := Options{Kind: "namespace", Items: items, Current: "ns-01", Describe: func(item string) string {
+		if item == "prod" {
+			return "v1.30.2, 3 nodes"
+		}
+		return ""
+	}}
+
+	var out bytes.Buffer
+	got, err := Plain(opts, strings.NewReader("ns-0\n11\n10\n"), &out)
+	require.NoError(t, err)
+	// Names take precedence over numbers
+	assert.Equal(t, "10", got)
+	assert.Equal(t, ` 1) ns-00
+ 2) ns-01 (current)
+ 3) ns-02
+ 4) ns-03
+ 5) ns-04
+ 6) ns-05
+ 7) ns-06
+ 8) ns-07
+ 9) 10
+10) prod - v1.30.2, 3 nodes
+Select namespace (number, name or prefix) [ns-01]: "ns-0" matches several namespaces: ns-00, ns-01, ns-02, ns-03, ns-04, ns-05, ns-06, ns-07
+Select namespace (number, name or prefix) [ns-01]: no namespace number 11, enter 1-10
+Select namespace (number, name or prefix) [ns-01]: `, out.String())
+}
+
+func TestPlain_Pinned(t *testing.T) {
+	var out bytes.Buffer
+	opts := Options{Kind: "context", Items: contexts, Current: "dev", Pinned: []string{"staging", "missing", "prod-eu"}}
+	got, err := Plain(opts, strings.NewReader("2\n"), &out)
+	require.NoError(t, err)
+	assert.Equal(t, "prod-eu", got)
+	assert.Equal(t, `1) staging
+2) prod-eu
+---
+3) dev (current)
+4) kind-kind
+5) prod-us
+Select context (number, name or prefix) [dev]: `, out.String())
+}
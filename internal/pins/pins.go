@@ -0,0 +1,112 @@
+// Package pins stores the favorite contexts, and the favorite namespaces of
+// each context, that pickers and completions show first
+package pins
+
+import (
+	"slices"
+
+	"github.com/camaeel/kubectl-ctx/internal/state"
+)
+
+const fileName = "pins.json"
+
+// Pins are the pinned contexts and namespaces, in the order they were pinned
+type Pins struct {
+	Contexts []string `json:"contexts,omitempty"`
+	// Namespaces maps context names to their pinned namespaces
+	Namespaces map[string][]string `json:"namespaces,omitempty"`
+}
+
+// Load reads the pins
+func Load() (*Pins, error) {
+	p, err := state.Load[Pins](fileName)
+	return &p, err
+}
+
+// Update applies mutate to the stored pins
+func Update(mutate func(p *Pins) error) error {
+	return state.Update(fileName, mutate)
+}
+
+// PinContext pins a context and reports whether it wasn't pinned already
+func (p *Pins) PinContext(name string) bool {
+	return add(&p.Contexts, name)
+}
+
+// UnpinContext unpins a context and reports whether it was pinned
+func (p *Pins) UnpinContext(name string) bool {
+	return remove(&p.Contexts, name)
+}
+
+// NamespacesOf returns the pinned namespaces of a context
+func (p *Pins) NamespacesOf(contextName string) []string {
+	return p.Namespaces[contextName]
+}
+
+// PinNamespace pins a namespace of a context and reports whether it wasn't
+// pinned already
+func (p *Pins) PinNamespace(contextName, namespace string) bool {
+	if p.Namespaces == nil {
+		p.Namespaces = map[string][]string{}
+	}
+	namespaces := p.Namespaces[contextName]
+	added := add(&namespaces, namespace)
+	p.Namespaces[contextName] = namespaces
+	return added
+}
+
+// UnpinNamespace unpins a namespace of a context and reports whether it
+// was pinned
+func (p *Pins) UnpinNamespace(contextName, namespace string) bool {
+	namespaces := p.Namespaces[contextName]
+	removed := remove(&namespaces, namespace)
+	if len(namespaces) == 0 {
+		delete(p.Namespaces, contextName)
+	} else {
+		p.Namespaces[contextName] = namespaces
+	}
+	return removed
+}
+
+// RenameContext moves the pins of a renamed context to its new name
+func (p *Pins) RenameContext(oldName, newName string) {
+	if i := slices.Index(p.Contexts, oldName); i >= 0 {
+		p.Contexts[i] = newName
+	}
+	if namespaces, ok := p.Namespaces[oldName]; ok {
+		delete(p.Namespaces, oldName)
+		p.Namespaces[newName] = namespaces
+	}
+}
+
+// First returns items with the pinned ones moved to the front, in the order
+// they were pinned, and the number of pinned items
+func First(items, pinned []string) ([]string, int) {
+	sorted := make([]string, 0, len(items))
+	for _, name := range pinned {
+		if slices.Contains(items, name) {
+			sorted = append(sorted, name)
+		}
+	}
+	n := len(sorted)
+	for _, item := range items {
+		if !slices.Contains(sorted[:n], item) {
+			sorted = append(sorted, item)
+		}
+	}
+	return sorted, n
+}
+
+func add(list *[]string, name string) bool {
+	if slices.Contains(*list, name) {
+		return false
+	}
+	*list = append(*list, name)
+	return true
+}
+
+func remove(list *[]string, name string) bool {
+	n := len(*list)
+	*list = slices.DeleteFunc(*list, func(item string) bool { return item == name })
+	return len(*list) < n
+}
@@ -0,0 +1,64 @@
+package pins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPins(t *testing.T) {
+	p := &Pins{}
+
+	assert.True(t, p.PinContext("prod"))
+	assert.True(t, p.PinContext("dev"))
+	assert.False(t, p.PinContext("prod"))
+	assert.Equal(t, []string{"prod", "dev"}, p.Contexts)
+
+	assert.True(t, p.PinNamespace("prod", "payments"))
+	assert.True(t, p.PinNamespace("prod", "kube-system"))
+	assert.False(t, p.PinNamespace("prod", "payments"))
+	assert.Equal(t, []string{"payments", "kube-system"}, p.NamespacesOf("prod"))
+	assert.Empty(t, p.NamespacesOf("dev"))
+
+	p.RenameContext("prod", "production")
+	assert.Equal(t, []string{"production", "dev"}, p.Contexts)
+	assert.Equal(t, []string{"payments", "kube-system"}, p.NamespacesOf("production"))
+
+	assert.True(t, p.UnpinContext("production"))
+	assert.False(t, p.UnpinContext("production"))
+	assert.Equal(t, []string{"dev"}, p.Contexts)
+
+	assert.True(t, p.UnpinNamespace("production", "payments"))
+	assert.True(t, p.UnpinNamespace("production", "kube-system"))
+	assert.False(t, p.UnpinNamespace("production", "kube-system"))
+	assert.Empty(t, p.Namespaces)
+}
+
+func TestUpdate(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	p, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, p.Contexts)
+
+	require.NoError(t, Update(func(p *Pins) error {
+		p.PinContext("prod")
+		p.PinNamespace("dev", "web")
+		return nil
+	}))
+
+	p, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, &Pins{Contexts: []string{"prod"}, Namespaces: map[string][]string{"dev": {"web"}}}, p)
+}
+
+func TestFirst(t *testing.T) {
+	items, n := First([]string{"a", "b", "c", "d"}, []string{"d", "missing", "b"})
+	assert.Equal(t, []string{"d", "b", "a", "c"}, items)
+	assert.Equal(t, 2, n)
+
+	items, n = First([]string{"a", "b"}, nil)
+	assert.Equal(t, []string{"a", "b"}, items)
+	assert.Zero(t, n)
+}
@@ -0,0 +1,88 @@
+// Package state reads and writes the JSON files kept in the tool's state
+// directory
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/camaeel/kubectl-ctx/internal/utils/atomicfile"
+	"github.com/camaeel/kubectl-ctx/internal/utils/filelock"
+	"github.com/camaeel/kubectl-ctx/internal/utils/paths"
+)
+
+// Path returns the location of the state file name
+func Path(name string) (string, error) {
+	dir, err := paths.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Load reads the state file name. A missing or corrupt file is the zero
+// value, as state is only a convenience.
+func Load[T any](name string) (T, error) {
+	var v T
+	path, err := Path(name)
+	if err != nil {
+		return v, err
+	}
+	return loadFile[T](path), nil
+}
+
+func loadFile[T any](path string) T {
+	var v T
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return v
+	}
+	if err == nil {
+		err = json.Unmarshal(content, &v)
+	}
+	if err != nil {
+		slog.Debug("Ignoring state file", "file", path, "error", err)
+		var zero T
+		return zero
+	}
+	return v
+}
+
+// Update applies mutate to the state file name while holding its lock, so
+// concurrent invocations don't lose changes. The file is written only if
+// mutate succeeds.
+func Update[T any](name string, mutate func(v *T) error) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	unlock, err := filelock.Lock([]string{path})
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	v := loadFile[T](path)
+	if err := mutate(&v); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counter struct {
+	Count int `json:"count"`
+}
+
+func TestUpdate(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	increment := func(c *counter) error {
+		c.Count++
+		return nil
+	}
+	require.NoError(t, Update("counter.json", increment))
+	require.NoError(t, Update("counter.json", increment))
+
+	c, err := Load[counter]("counter.json")
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Count)
+
+	// Nothing is written when mutate fails
+	err = Update("counter.json", func(c *counter) error {
+		c.Count = 100
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+	c, err = Load[counter]("counter.json")
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Count)
+
+	path, err := Path("counter.json")
+	require.NoError(t, err)
+	assert.NoFileExists(t, path+".lock")
+	assert.NoFileExists(t, path+".tmp")
+}
+
+func TestLoad_MissingOrCorrupt(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	c, err := Load[counter]("counter.json")
+	require.NoError(t, err)
+	assert.Zero(t, c)
+
+	require.NoError(t, Update("counter.json", func(c *counter) error {
+		c.Count = 5
+		return nil
+	}))
+	path, err := Path("counter.json")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(`{"count": "five"`), 0600))
+
+	c, err = Load[counter]("counter.json")
+	require.NoError(t, err)
+	assert.Zero(t, c)
+}
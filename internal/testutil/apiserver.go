@@ -0,0 +1,72 @@
+package testutil
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// FakeNodes is the number of nodes the fake API server reports
+const FakeNodes = 3
+
+// NewFakeAPIServer starts an HTTP server answering the few Kubernetes API
+// calls the tools make: namespace and node listing and server version.
+// Returns the server URL; the server is closed when the test finishes.
+func NewFakeAPIServer(t *testing.T, namespaces []string) string {
+	t.Helper()
+
+	server := httptest.NewServer(fakeAPIHandler(namespaces))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// NewFakeTLSAPIServer is like NewFakeAPIServer, but serves HTTPS, which
+// client-go requires to send credentials. Returns the server URL and the
+// PEM-encoded CA certificate to trust.
+func NewFakeTLSAPIServer(t *testing.T, namespaces []string) (string, []byte) {
+	t.Helper()
+
+	server := httptest.NewTLSServer(fakeAPIHandler(namespaces))
+	t.Cleanup(server.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server.URL, ca
+}
+
+func fakeAPIHandler(namespaces []string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/namespaces", func(w http.ResponseWriter, _ *http.Request) {
+		items := make([]map[string]any, 0, len(namespaces))
+		for _, ns := range namespaces {
+			items = append(items, map[string]any{"metadata": map[string]any{"name": ns}})
+		}
+		writeJSON(w, map[string]any{"kind": "NamespaceList", "apiVersion": "v1", "items": items})
+	})
+	mux.HandleFunc("/api/v1/nodes", func(w http.ResponseWriter, r *http.Request) {
+		items := make([]map[string]any, 0, FakeNodes)
+		for i := range FakeNodes {
+			items = append(items, map[string]any{"metadata": map[string]any{"name": fmt.Sprintf("node-%d", i)}})
+		}
+		// Paginate like an API server reading from etcd
+		list := map[string]any{}
+		if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit < len(items) {
+			list = map[string]any{"continue": "next", "remainingItemCount": len(items) - limit}
+			items = items[:limit]
+		}
+		writeJSON(w, map[string]any{"kind": "NodeList", "apiVersion": "v1", "metadata": list, "items": items})
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"major": "1", "minor": "36", "gitVersion": "v1.36.3"})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
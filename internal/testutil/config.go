@@ -0,0 +1,26 @@
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/camaeel/kubectl-ctx/internal/config"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+// WriteConfig writes c, at the current version, as the tool config file of
+// the test and returns its path
+func WriteConfig(t *testing.T, c *config.Config) string {
+	t.Helper()
+
+	path, err := config.Path()
+	require.NoError(t, err)
+	c.Version = config.Version
+	content, err := yaml.Marshal(c)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, content, 0600))
+	return path
+}
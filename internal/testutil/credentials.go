@@ -0,0 +1,76 @@
+package testutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// NewJWT returns an unsigned JWT with the given expiry
+func NewJWT(expiry time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	payload := fmt.Sprintf(`{"sub":"test","exp":%d}`, expiry.Unix())
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(payload)) + ".sig"
+}
+
+// NewExecPlugin writes a stub exec credential plugin returning token. Each
+// run appends a line to the returned calls file. An empty token makes the
+// plugin fail.
+func NewExecPlugin(t *testing.T, token string) (plugin, calls string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub exec plugin needs a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	plugin = filepath.Join(dir, "exec-plugin")
+	calls = filepath.Join(dir, "calls")
+
+	script := fmt.Sprintf("#!/bin/sh\necho called >> %q\n", calls)
+	if token == "" {
+		script += "echo 'login failed' >&2\nexit 1\n"
+	} else {
+		script += fmt.Sprintf(`echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":%q}}'`+"\n", token)
+	}
+
+	if err := os.WriteFile(plugin, []byte(script), 0700); err != nil {
+		t.Fatalf("Failed to write exec plugin: %v", err)
+	}
+	return plugin, calls
+}
+
+// NewCertificate returns a PEM-encoded self-signed client certificate
+// expiring at notAfter
+func NewCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.Add(-30 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
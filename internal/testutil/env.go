@@ -0,0 +1,25 @@
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// RunIsolated runs the tests of m with the XDG state, config and cache
+// directories in a temporary directory, keeping the user's tool config,
+// usage and cache out of the tests. Call it from TestMain.
+func RunIsolated(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "kubectl-ctx-test")
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	for _, env := range []string{"XDG_STATE_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME"} {
+		if err := os.Setenv(env, filepath.Join(dir, env)); err != nil {
+			panic(err)
+		}
+	}
+	return m.Run()
+}
@@ -14,6 +14,13 @@ import (
 func CreateKubeconfig(t *testing.T, currentContext string, contexts map[string]string) string {
 	t.Helper()
 
+	return CreateKubeconfigWithServer(t, "https://localhost:6443", currentContext, contexts)
+}
+
+// CreateKubeconfigWithServer works like CreateKubeconfig but points the test cluster at the given server URL.
+func CreateKubeconfigWithServer(t *testing.T, server, currentContext string, contexts map[string]string) string {
+	t.Helper()
+
 	tmpDir := t.TempDir()
 	kubeconfigPath := filepath.Join(tmpDir, "config")
 
@@ -23,7 +30,7 @@ func CreateKubeconfig(t *testing.T, currentContext string, contexts map[string]s
 	content += "current-context: " + currentContext + "\n"
 	content += "clusters:\n"
 	content += "- cluster:\n"
-	content += "    server: https://localhost:6443\n"
+	content += "    server: " + server + "\n"
 	content += "  name: test-cluster\n"
 	content += "contexts:\n"
 	for ctx, ns := range contexts {
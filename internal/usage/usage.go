@@ -0,0 +1,70 @@
+// Package usage records how often and how recently contexts are switched to,
+// so they can be ranked by frecency
+package usage
+
+import (
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/state"
+)
+
+const fileName = "usage.json"
+
+// Entry records the switches to one context
+type Entry struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// Frecency scores an entry by its switch count, weighted by how recently
+// the last switch happened
+func (e Entry) Frecency(now time.Time) float64 {
+	age := now.Sub(e.Last)
+	switch {
+	case age < time.Hour:
+		return float64(e.Count) * 4
+	case age < 24*time.Hour:
+		return float64(e.Count) * 2
+	case age < 7*24*time.Hour:
+		return float64(e.Count) / 2
+	default:
+		return float64(e.Count) / 4
+	}
+}
+
+// Usage maps context names to their switches
+type Usage map[string]Entry
+
+// Load reads the usage of all contexts
+func Load() (Usage, error) {
+	u, err := state.Load[Usage](fileName)
+	if u == nil {
+		u = Usage{}
+	}
+	return u, err
+}
+
+// Record counts a switch to a context
+func Record(name string, now time.Time) error {
+	return state.Update(fileName, func(u *Usage) error {
+		if *u == nil {
+			*u = Usage{}
+		}
+		e := (*u)[name]
+		e.Count++
+		e.Last = now.UTC()
+		(*u)[name] = e
+		return nil
+	})
+}
+
+// Rename moves the usage of a renamed context to its new name
+func Rename(oldName, newName string) error {
+	return state.Update(fileName, func(u *Usage) error {
+		if e, ok := (*u)[oldName]; ok {
+			delete(*u, oldName)
+			(*u)[newName] = e
+		}
+		return nil
+	})
+}
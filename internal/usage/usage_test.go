@@ -0,0 +1,80 @@
+package usage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/camaeel/kubectl-ctx/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, Record("dev", now))
+	require.NoError(t, Record("dev", now.Add(time.Minute)))
+	require.NoError(t, Record("prod", now))
+
+	u, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, Usage{
+		"dev":  {Count: 2, Last: now.Add(time.Minute)},
+		"prod": {Count: 1, Last: now},
+	}, u)
+
+	require.NoError(t, Rename("dev", "development"))
+	require.NoError(t, Rename("missing", "other"))
+	u, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, Entry{Count: 2, Last: now.Add(time.Minute)}, u["development"])
+	assert.NotContains(t, u, "dev")
+	assert.NotContains(t, u, "other")
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	u, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, u)
+
+	path, err := state.Path(fileName)
+	require.NoError(t, err)
+	require.NoError(t, Record("dev", time.Now()))
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0600))
+
+	u, err = Load()
+	require.NoError(t, err)
+	assert.Empty(t, u)
+
+	// Recording starts over
+	require.NoError(t, Record("dev", time.Now()))
+	u, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 1, u["dev"].Count)
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		entry Entry
+		want  float64
+	}{
+		{name: "last hour", entry: Entry{Count: 3, Last: now.Add(-time.Minute)}, want: 12},
+		{name: "last day", entry: Entry{Count: 3, Last: now.Add(-2 * time.Hour)}, want: 6},
+		{name: "last week", entry: Entry{Count: 3, Last: now.Add(-48 * time.Hour)}, want: 1.5},
+		{name: "older", entry: Entry{Count: 3, Last: now.Add(-30 * 24 * time.Hour)}, want: 0.75},
+		{name: "never", entry: Entry{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.entry.Frecency(now))
+		})
+	}
+}
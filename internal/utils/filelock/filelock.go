@@ -0,0 +1,79 @@
+// Package filelock serializes writers of a file through a lock file next to
+// it, following client-go's convention for kubeconfig files, so kubectl
+// honors the same locks.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+var (
+	// Timeout is how long to wait for other writers to release their locks
+	Timeout = 10 * time.Second
+	// lockRetryInterval is the delay between attempts to acquire a lock
+	lockRetryInterval = 25 * time.Millisecond
+)
+
+// lockName returns the lock file path, following client-go's convention
+func lockName(filename string) string {
+	return filename + ".lock"
+}
+
+// Lock acquires the lock files of all given files, waiting up to Timeout
+// for other writers (including kubectl) to release them.
+// Files are locked in sorted order, like client-go does, to avoid deadlocks.
+// The returned function releases all acquired locks.
+func Lock(files []string) (func(), error) {
+	sorted := slices.Clone(files)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var locked []string
+	unlock := func() {
+		for _, filename := range locked {
+			if err := os.Remove(lockName(filename)); err != nil {
+				slog.Warn("Failed to remove lock file", "file", lockName(filename), "error", err)
+			}
+		}
+	}
+
+	for _, filename := range sorted {
+		if err := lockFile(filename); err != nil {
+			unlock()
+			return nil, err
+		}
+		locked = append(locked, filename)
+	}
+
+	return unlock, nil
+}
+
+func lockFile(filename string) error {
+	// Make sure the dir exists before we try to create a lock file
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(Timeout)
+	for {
+		f, err := os.OpenFile(lockName(filename), os.O_CREATE|os.O_EXCL, 0)
+		if err == nil {
+			return f.Close()
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("failed to lock %s: %w", filename, err)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for lock %s; remove it if no other process is writing the file", lockName(filename))
+		}
+		slog.Debug("Waiting for lock", "file", lockName(filename))
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,59 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock_WaitsForRelease(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config")
+
+	unlock, err := Lock([]string{file})
+	require.NoError(t, err)
+	assert.FileExists(t, lockName(file))
+
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		unlock()
+		close(released)
+	}()
+
+	unlock2, err := Lock([]string{file})
+	require.NoError(t, err)
+	<-released
+	unlock2()
+	assert.NoFileExists(t, lockName(file))
+}
+
+func TestLock_Timeout(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(lockName(file), nil, 0600))
+
+	oldTimeout := Timeout
+	Timeout = 50 * time.Millisecond
+	t.Cleanup(func() { Timeout = oldTimeout })
+
+	_, err := Lock([]string{file})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestLock_ReleasesPartialLocksOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	require.NoError(t, os.WriteFile(lockName(second), nil, 0600))
+
+	oldTimeout := Timeout
+	Timeout = 0
+	t.Cleanup(func() { Timeout = oldTimeout })
+
+	_, err := Lock([]string{second, first})
+	require.Error(t, err)
+	assert.NoFileExists(t, lockName(first))
+}
@@ -2,65 +2,223 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 const (
 	colorRed    = "\033[31m"
 	colorOrange = "\033[33m"
+	colorGray   = "\033[90m"
 	colorReset  = "\033[0m"
 )
 
+// EnvLogFormat selects the log format; set it to "json" for structured logs
+const EnvLogFormat = "KUBECTL_CTX_LOG"
+
+var (
+	// level is shared by all handlers so flags parsed after setup still apply
+	level = new(slog.LevelVar)
+
+	verbose int
+	quiet   bool
+)
+
+// Options configures a CLI handler
+type Options struct {
+	// Level is the minimum level to log (defaults to Info)
+	Level slog.Leveler
+	// Color enables ANSI colors for warnings, errors and debug messages
+	Color bool
+}
+
 // cliHandler is a custom slog handler for clean CLI output
 type cliHandler struct {
-	w io.Writer
+	mu     *sync.Mutex
+	w      io.Writer
+	opts   Options
+	attrs  []slog.Attr
+	groups []string
 }
 
-func (h *cliHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= slog.LevelInfo
+// NewCLIHandler creates a handler printing messages without timestamps or level names
+func NewCLIHandler(w io.Writer, opts *Options) slog.Handler {
+	h := &cliHandler{mu: &sync.Mutex{}, w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	return h
+}
+
+func (h *cliHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.opts.Level.Level()
 }
 
 func (h *cliHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+
 	// Apply colors based on log level
-	switch {
-	case r.Level >= slog.LevelError:
-		_, _ = fmt.Fprint(h.w, colorRed)
-	case r.Level >= slog.LevelWarn:
-		_, _ = fmt.Fprint(h.w, colorOrange)
+	color := ""
+	if h.opts.Color {
+		switch {
+		case r.Level >= slog.LevelError:
+			color = colorRed
+		case r.Level >= slog.LevelWarn:
+			color = colorOrange
+		case r.Level < slog.LevelInfo:
+			color = colorGray
+		}
 	}
+	sb.WriteString(color)
 
 	// Print message without "msg=" prefix
-	_, _ = fmt.Fprint(h.w, r.Message)
+	sb.WriteString(r.Message)
 
 	// Print attributes as key=value without quotes
+	for _, a := range h.attrs {
+		writeAttr(&sb, "", a)
+	}
+	prefix := groupPrefix(h.groups)
 	r.Attrs(func(a slog.Attr) bool {
-		_, _ = fmt.Fprintf(h.w, " %s=%v", a.Key, a.Value.Any())
+		writeAttr(&sb, prefix, a)
 		return true
 	})
 
 	// Reset color
-	if r.Level >= slog.LevelWarn {
-		_, _ = fmt.Fprint(h.w, colorReset)
+	if color != "" {
+		sb.WriteString(colorReset)
 	}
+	sb.WriteString("\n")
 
-	_, _ = fmt.Fprintln(h.w)
-	return nil
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, sb.String())
+	return err
 }
 
-func (h *cliHandler) WithAttrs(_ []slog.Attr) slog.Handler {
-	return h
+func (h *cliHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	// Qualify the attributes with the groups opened so far
+	prefix := groupPrefix(h.groups)
+	h2 := h.clone()
+	for _, a := range attrs {
+		a.Key = prefix + a.Key
+		h2.attrs = append(h2.attrs, a)
+	}
+	return h2
 }
 
-func (h *cliHandler) WithGroup(_ string) slog.Handler {
-	return h
+func (h *cliHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := h.clone()
+	h2.groups = append(h2.groups, name)
+	return h2
+}
+
+func (h *cliHandler) clone() *cliHandler {
+	return &cliHandler{
+		mu:     h.mu,
+		w:      h.w,
+		opts:   h.opts,
+		attrs:  slices.Clip(h.attrs),
+		groups: slices.Clip(h.groups),
+	}
+}
+
+func groupPrefix(groups []string) string {
+	if len(groups) == 0 {
+		return ""
+	}
+	return strings.Join(groups, ".") + "."
+}
+
+func writeAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	// Flatten groups into dotted keys
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			writeAttr(sb, prefix, ga)
+		}
+		return
+	}
+
+	_, _ = fmt.Fprintf(sb, " %s%s=%v", prefix, a.Key, a.Value.Any())
+}
+
+// ColorEnabled reports whether colored output should be written to w.
+// Colors are disabled by NO_COLOR, TERM=dumb, or when w is not a terminal.
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// NewHandler creates the handler used by the commands: JSON when
+// KUBECTL_CTX_LOG=json, otherwise the CLI handler
+func NewHandler(w io.Writer) slog.Handler {
+	if os.Getenv(EnvLogFormat) == "json" {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	}
+	return NewCLIHandler(w, &Options{Level: level, Color: ColorEnabled(w)})
 }
 
 // SetupCLILogger configures slog for clean CLI output
 // Removes timestamps and log levels for a better user experience
 func SetupCLILogger() {
-	logger := slog.New(&cliHandler{w: os.Stderr})
-	slog.SetDefault(logger)
+	slog.SetDefault(slog.New(NewHandler(os.Stderr)))
+}
+
+// AddFlags registers the -v/--verbose and -q/--quiet flags
+func AddFlags(fs *pflag.FlagSet) {
+	fs.CountVarP(&verbose, "verbose", "v", "increase log verbosity (show debug messages)")
+	fs.BoolVarP(&quiet, "quiet", "q", false, "only log errors")
+}
+
+// ApplyFlags sets the log level from the parsed verbosity flags
+func ApplyFlags() error {
+	if verbose > 0 && quiet {
+		return errors.New("--verbose and --quiet are mutually exclusive")
+	}
+	SetVerbosity(verbose, quiet)
+	return nil
+}
+
+// SetVerbosity sets the log level: quiet shows only errors,
+// any verbosity above zero enables debug messages
+func SetVerbosity(v int, q bool) {
+	switch {
+	case q:
+		level.Set(slog.LevelError)
+	case v > 0:
+		level.Set(slog.LevelDebug)
+	default:
+		level.Set(slog.LevelInfo)
+	}
 }
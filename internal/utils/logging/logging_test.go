@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLIHandler_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	lvl := new(slog.LevelVar)
+	logger := slog.New(NewCLIHandler(&buf, &Options{Level: lvl}))
+
+	logger.Debug("hidden")
+	logger.Info("shown", "context", "prod")
+	assert.Equal(t, "shown context=prod\n", buf.String())
+
+	buf.Reset()
+	lvl.Set(slog.LevelDebug)
+	logger.Debug("visible")
+	assert.Equal(t, "visible\n", buf.String())
+}
+
+func TestCLIHandler_Color(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, &Options{Color: true}))
+
+	logger.Warn("careful")
+	logger.Info("plain")
+	assert.Equal(t, colorOrange+"careful"+colorReset+"\nplain\n", buf.String())
+}
+
+func TestCLIHandler_AttrsAndGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewCLIHandler(&buf, nil))
+
+	logger.With("file", "config").WithGroup("ctx").With("name", "prod").Info("msg", "ns", "web",
+		slog.Group("server", "host", "example.com"))
+	assert.Equal(t, "msg file=config ctx.name=prod ctx.ns=web ctx.server.host=example.com\n", buf.String())
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+	assert.False(t, ColorEnabled(&bytes.Buffer{}), "non-file writers are never colored")
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, ColorEnabled(&bytes.Buffer{}))
+}
+
+func TestNewHandler_JSON(t *testing.T) {
+	t.Setenv(EnvLogFormat, "json")
+
+	var buf bytes.Buffer
+	slog.New(NewHandler(&buf)).Info("switched", "context", "prod")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "switched", record["msg"])
+	assert.Equal(t, "prod", record["context"])
+}
+
+func TestSetVerbosity(t *testing.T) {
+	t.Cleanup(func() { SetVerbosity(0, false) })
+
+	SetVerbosity(1, false)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	SetVerbosity(0, true)
+	assert.Equal(t, slog.LevelError, level.Level())
+
+	SetVerbosity(0, false)
+	assert.Equal(t, slog.LevelInfo, level.Level())
+}
+
+func TestApplyFlags_Conflict(t *testing.T) {
+	verbose, quiet = 1, true
+	t.Cleanup(func() { verbose, quiet = 0, false })
+
+	assert.Error(t, ApplyFlags())
+}
@@ -0,0 +1,55 @@
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AppName is the directory name used below the XDG base directories
+const AppName = "kubectl-ctx"
+
+// StateDir returns the directory for persistent tool state such as backups
+// ($XDG_STATE_HOME/kubectl-ctx, defaulting to ~/.local/state/kubectl-ctx)
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// ConfigDir returns the directory of the tool's configuration file
+// ($XDG_CONFIG_HOME/kubectl-ctx, defaulting to ~/.config/kubectl-ctx)
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// CacheDir returns the directory for data that can be recomputed, such as
+// cluster info ($XDG_CACHE_HOME/kubectl-ctx, defaulting to ~/.cache/kubectl-ctx)
+func CacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// ExpandHome replaces a leading ~ in path with the home directory
+func ExpandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && !os.IsPathSeparator(rest[0])) {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return home + rest, nil
+}
+
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, AppName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, fallback, AppName), nil
+}
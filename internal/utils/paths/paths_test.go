@@ -0,0 +1,65 @@
+package paths
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateDir(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	dir, err := StateDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/xdg/state", AppName), dir)
+}
+
+func TestStateDir_Fallback(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// Relative paths are invalid per the XDG spec and must be ignored
+	t.Setenv("XDG_STATE_HOME", "relative")
+
+	dir, err := StateDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".local", "state", AppName), dir)
+}
+
+func TestCacheDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/xdg/cache")
+	dir, err := CacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/xdg/cache", AppName), dir)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "")
+	dir, err = CacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".cache", AppName), dir)
+}
+
+func TestConfigDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	dir, err := ConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/xdg/config", AppName), dir)
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := map[string]string{
+		"~":                  home,
+		"~/.kube/*.yaml":     home + "/.kube/*.yaml",
+		"~other/config":      "~other/config",
+		"/etc/kube/~/config": "/etc/kube/~/config",
+	}
+	for path, want := range tests {
+		got, err := ExpandHome(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, path)
+	}
+}
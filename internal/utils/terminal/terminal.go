@@ -0,0 +1,19 @@
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsInteractive reports whether both stdin and stdout are attached to a terminal.
+// It is a variable so tests can simulate an interactive session.
+var IsInteractive = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// IsDumb reports whether the terminal lacks cursor control (TERM=dumb), as in
+// Emacs shells and some CI consoles
+func IsDumb() bool {
+	return os.Getenv("TERM") == "dumb"
+}
@@ -67,11 +67,11 @@ echo
 echo "1. Testing context listing (should show contexts from both files):"
 echo "   Expected: cluster1-context, cluster2-context, shared-context (from config1)"
 echo
-./kubectl-ctx 2>&1 | head -10 || echo "Interactive test - skipped"
+./kubectl-ctx < /dev/null
 echo
 
 echo "2. Testing current context (should be from first file):"
-CURRENT=$(./kubectl-ctx 2>&1 | head -1)
+CURRENT=$(./kubectl-ctx --current)
 echo "   Current: $CURRENT"
 echo
 